@@ -0,0 +1,70 @@
+package Logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 切分后旧文件的命名方式
+const (
+	BackupNameDated     = iota // 按日期命名并在同一周期内追加序号，如 2006-01-02.1.log
+	BackupNameTimestamp        // lumberjack 风格，当前文件名固定，切分时将旧文件重命名为 name-2006-01-02T15-04-05.000.log
+)
+
+// lumberjack 风格的旧文件名中的时间布局
+const backupTimeLayout = "2006-01-02T15-04-05.000"
+
+// 未设置 Prefix 时 lumberjack 风格使用的文件名
+const defaultBackupBaseName = "app"
+
+// 设置切分后旧文件的命名方式，BackupNameDated 或 BackupNameTimestamp，在 SetLogger 之前调用生效
+func (l *Log) SetBackupNameScheme(scheme int) {
+	l.BackupNameScheme = scheme
+}
+
+// lumberjack 风格下文件名的主体，取 Prefix 去掉末尾的分隔符
+func (l *Log) backupBaseName() string {
+	base := strings.TrimRight(l.fileNamePattern().Prefix, "-_.")
+	if base == "" {
+		return defaultBackupBaseName
+	}
+	return base
+}
+
+// 当前写入的文件名，lumberjack 风格下固定为 name.log，与日期和序号无关
+func (l *Log) activeFileName(date time.Time, index int) string {
+	if l.BackupNameScheme == BackupNameTimestamp {
+		return l.backupBaseName() + l.fileNamePattern().Suffix
+	}
+	return l.fileNamePattern().fileName(date, index)
+}
+
+// 将切分前的文件重命名为带时间戳的旧文件，同一毫秒内多次切分时顺延时间戳避免覆盖，返回新路径
+func (l *Log) renameToBackup(path string, now time.Time) (string, error) {
+	pattern := l.fileNamePattern()
+	for {
+		backup := filepath.Join(filepath.Dir(path), l.backupBaseName()+"-"+now.Format(backupTimeLayout)+pattern.Suffix)
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			return backup, os.Rename(path, backup)
+		}
+		now = now.Add(time.Millisecond)
+	}
+}
+
+// 从 lumberjack 风格的旧文件名中解析切分时间，不是该格式时返回 false
+func (l *Log) backupTime(name string) (time.Time, bool) {
+	name = strings.TrimSuffix(name, ".gz")
+	prefix := l.backupBaseName() + "-"
+	suffix := l.fileNamePattern().Suffix
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return time.Time{}, false
+	}
+	location := time.Local
+	if l.UseUTC {
+		location = time.UTC
+	}
+	t, err := time.ParseInLocation(backupTimeLayout, name[len(prefix):len(name)-len(suffix)], location)
+	return t, err == nil
+}
@@ -0,0 +1,84 @@
+package Logger
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLog_BackupNameTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetBackupNameScheme(BackupNameTimestamp)
+	LogClient.SetFileNamePattern(FileNamePattern{Prefix: "svc-"})
+	LogClient.SetMaxSize(300)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	for i := 0; i < 10; i++ {
+		LogClient.Infof("backup message %d", i)
+	}
+	LogClient.Flush()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backupName := regexp.MustCompile(`^svc-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3}\.log$`)
+	var backups int
+	for _, entry := range entries {
+		switch {
+		case entry.Name() == "svc.log":
+		case backupName.MatchString(entry.Name()):
+			backups++
+		default:
+			t.Errorf("unexpected file %q", entry.Name())
+		}
+	}
+	if backups == 0 {
+		t.Fatal("expected timestamped backups after rotation")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "svc.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(data), "message:backup message 9\n") {
+		t.Errorf("expected the active file to keep a stable name, got %q", data)
+	}
+}
+
+func TestLog_ClearOldTimestampBackups(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	oldName := "app-" + now.AddDate(0, 0, -10).Format(backupTimeLayout) + ".log"
+	recentName := "app-" + now.AddDate(0, 0, -1).Format(backupTimeLayout) + ".log"
+	files := map[string]time.Time{
+		// 文件名中的时间优先于修改时间
+		oldName:    now,
+		recentName: now.AddDate(0, 0, -10),
+		// 正在写入的文件即使很久没有修改也不删除
+		"app.log": now.AddDate(0, 0, -10),
+	}
+	for name, modTime := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("line\n"), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l := &Log{FilePath: dir, MaxDay: 3, BackupNameScheme: BackupNameTimestamp}
+	if err := l.clearOldLogs(); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{oldName: false, recentName: true, "app.log": true} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if exists := err == nil; exists != want {
+			t.Errorf("%s exists = %v, want %v", name, exists, want)
+		}
+	}
+}
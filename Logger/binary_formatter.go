@@ -0,0 +1,196 @@
+package Logger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// protobuf 编码中用到的字段类型
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// BinaryFormatter 中各字段的编号
+const (
+	binaryFieldLevel   = 1
+	binaryFieldTime    = 2
+	binaryFieldMessage = 3
+	binaryFieldFields  = 4
+)
+
+// 单条二进制日志允许的最大长度，防止解码损坏的数据时分配过多内存
+const maxBinaryEntrySize = 64 << 20
+
+// 带长度前缀的 protobuf 格式，每条日志先写 varint 长度，再写与下面定义兼容的消息体：
+//
+//	message Entry {
+//	  int32 level = 1;
+//	  int64 time_unix_nano = 2;
+//	  string message = 3;
+//	  map<string, string> fields = 4;
+//	}
+//
+// 字段值用 fmt.Sprint 转为字符串，名称、调用处等其他信息不输出。ShowSequence 会破坏长度前缀，不应同时开启
+type BinaryFormatter struct{}
+
+func (BinaryFormatter) Format(e Entry) []byte {
+	var body []byte
+	body = appendVarintField(body, binaryFieldLevel, uint64(e.Level))
+	body = appendVarintField(body, binaryFieldTime, uint64(e.Time.UnixNano()))
+	body = appendBytesField(body, binaryFieldMessage, []byte(e.Message))
+	// 按键排序，保证相同的日志编码结果相同
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var pair []byte
+		pair = appendBytesField(pair, 1, []byte(k))
+		pair = appendBytesField(pair, 2, []byte(fmt.Sprint(e.Fields[k])))
+		body = appendBytesField(body, binaryFieldFields, pair)
+	}
+	out := appendUvarint(make([]byte, 0, len(body)+binary.MaxVarintLen32), uint64(len(body)))
+	return append(out, body...)
+}
+
+// 从 r 中读取一条 BinaryFormatter 写入的日志，数据读完时返回 io.EOF，字段值均为字符串
+func ReadBinaryEntry(r *bufio.Reader) (Entry, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Entry{}, err
+	}
+	if size > maxBinaryEntrySize {
+		return Entry{}, fmt.Errorf("binary entry too large: %d bytes", size)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return Entry{}, err
+	}
+	return DecodeBinaryEntry(body)
+}
+
+// 解码一条不含长度前缀的日志消息体，未知的字段会被跳过
+func DecodeBinaryEntry(data []byte) (Entry, error) {
+	var e Entry
+	for len(data) > 0 {
+		num, typ, value, rest, err := readField(data)
+		if err != nil {
+			return Entry{}, err
+		}
+		data = rest
+		switch {
+		case num == binaryFieldLevel && typ == wireVarint:
+			e.Level = int(int32(value.varint))
+		case num == binaryFieldTime && typ == wireVarint:
+			e.Time = time.Unix(0, int64(value.varint))
+		case num == binaryFieldMessage && typ == wireBytes:
+			e.Message = string(value.bytes)
+		case num == binaryFieldFields && typ == wireBytes:
+			k, v, err := decodeFieldPair(value.bytes)
+			if err != nil {
+				return Entry{}, err
+			}
+			if e.Fields == nil {
+				e.Fields = make(map[string]interface{})
+			}
+			e.Fields[k] = v
+		}
+	}
+	return e, nil
+}
+
+// 解码 map 中的一个键值对
+func decodeFieldPair(data []byte) (string, string, error) {
+	var key, value string
+	for len(data) > 0 {
+		num, typ, v, rest, err := readField(data)
+		if err != nil {
+			return "", "", err
+		}
+		data = rest
+		if typ != wireBytes {
+			continue
+		}
+		switch num {
+		case 1:
+			key = string(v.bytes)
+		case 2:
+			value = string(v.bytes)
+		}
+	}
+	return key, value, nil
+}
+
+// 解码出的字段值，varint 类型使用 varint，长度前缀类型使用 bytes
+type fieldValue struct {
+	varint uint64
+	bytes  []byte
+}
+
+// 读取一个字段，返回字段编号、类型、值和剩余的数据
+func readField(data []byte) (int, int, fieldValue, []byte, error) {
+	tag, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, 0, fieldValue{}, nil, fmt.Errorf("invalid binary entry: bad field tag")
+	}
+	data = data[n:]
+	num, typ := int(tag>>3), int(tag&7)
+	var value fieldValue
+	switch typ {
+	case wireVarint:
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, 0, fieldValue{}, nil, fmt.Errorf("invalid binary entry: bad varint in field %d", num)
+		}
+		value.varint, data = v, data[n:]
+	case wireBytes:
+		size, n := binary.Uvarint(data)
+		if n <= 0 || size > uint64(len(data)-n) {
+			return 0, 0, fieldValue{}, nil, fmt.Errorf("invalid binary entry: bad length in field %d", num)
+		}
+		value.bytes, data = data[n:n+int(size)], data[n+int(size):]
+	case wireFixed64, wireFixed32:
+		size := 8
+		if typ == wireFixed32 {
+			size = 4
+		}
+		if len(data) < size {
+			return 0, 0, fieldValue{}, nil, fmt.Errorf("invalid binary entry: truncated field %d", num)
+		}
+		data = data[size:]
+	default:
+		return 0, 0, fieldValue{}, nil, fmt.Errorf("invalid binary entry: unsupported wire type %d", typ)
+	}
+	return num, typ, value, data, nil
+}
+
+// 追加一个 varint 类型的字段
+func appendVarintField(b []byte, num int, v uint64) []byte {
+	b = appendUvarint(b, uint64(num)<<3|wireVarint)
+	return appendUvarint(b, v)
+}
+
+// 追加一个长度前缀类型的字段
+func appendBytesField(b []byte, num int, v []byte) []byte {
+	b = appendUvarint(b, uint64(num)<<3|wireBytes)
+	b = appendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// 追加 v 的 varint 编码
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
@@ -0,0 +1,77 @@
+package Logger
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBinaryFormatter_RoundTrip(t *testing.T) {
+	entries := []Entry{
+		{Level: Info, Time: time.Unix(0, 1700000000123456789), Message: "first"},
+		{Level: Warn, Time: time.Unix(0, 1700000001000000000), Message: "with fields", Fields: map[string]interface{}{"tenant": "acme", "user": "42"}},
+		{Level: Error, Time: time.Unix(0, -1), Message: "multi\nline 日志"},
+		{Level: Debug, Time: time.Unix(0, 0), Message: ""},
+	}
+	var buf bytes.Buffer
+	for _, e := range entries {
+		buf.Write(BinaryFormatter{}.Format(e))
+	}
+
+	r := bufio.NewReader(&buf)
+	for i, want := range entries {
+		got, err := ReadBinaryEntry(r)
+		if err != nil {
+			t.Fatalf("entry %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("entry %d = %+v, want %+v", i, got, want)
+		}
+	}
+	if _, err := ReadBinaryEntry(r); err != io.EOF {
+		t.Errorf("expected io.EOF after the last entry, got %v", err)
+	}
+
+	data := BinaryFormatter{}.Format(entries[1])
+	if _, err := ReadBinaryEntry(bufio.NewReader(bytes.NewReader(data[:len(data)-1]))); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF for a truncated entry, got %v", err)
+	}
+}
+
+func TestLog_BinaryFormatter(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetFormatter(BinaryFormatter{})
+	LogClient.SetLogger(Info, dir, 6)
+	LogClient.WithFields(map[string]interface{}{"count": 3}).Infof("binary %d", 1)
+	LogClient.Errorf("binary 2")
+	LogClient.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(bytes.NewReader(data))
+	first, err := ReadBinaryEntry(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Level != Info || first.Message != "binary 1" || first.Fields["count"] != "3" || first.Time.IsZero() {
+		t.Errorf("unexpected first entry %+v", first)
+	}
+	second, err := ReadBinaryEntry(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Level != Error || second.Message != "binary 2" || second.Fields != nil {
+		t.Errorf("unexpected second entry %+v", second)
+	}
+	if _, err := ReadBinaryEntry(r); err != io.EOF {
+		t.Errorf("expected exactly 2 entries, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package Logger
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 两次遍历目录校对索引之间的间隔，期间清理只使用内存中的索引
+var indexReconcileInterval = 24 * time.Hour
+
+// 已知日志文件的内存索引，切换文件时更新，清理时据此判断而不必每次遍历目录
+type logIndex struct {
+	mutex   sync.Mutex
+	files   map[string]logFileInfo // 已关闭的文件，记录关闭时的修改时间和大小
+	live    map[string]bool        // 正在写入的文件，清理时重新获取大小和修改时间
+	scanned time.Time              // 上次遍历目录的时间，零值表示需要遍历
+	walks   int64                  // 遍历目录的次数
+}
+
+// 清空索引，下次清理时重新遍历目录，切换目录、重新打开文件时调用
+func (x *logIndex) reset() {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	x.files = nil
+	x.live = nil
+	x.scanned = time.Time{}
+}
+
+// 记录正在写入的文件
+func (x *logIndex) watch(path string) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	if x.live == nil {
+		x.live = make(map[string]bool)
+	}
+	x.live[path] = true
+}
+
+// 文件不再写入后记录其最终的修改时间和大小
+func (x *logIndex) retire(path string) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	delete(x.live, path)
+	x.statLocked(path)
+}
+
+// 文件被压缩后以 .gz 文件替换原记录
+func (x *logIndex) compressed(path string) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	delete(x.files, path)
+	x.statLocked(path + ".gz")
+}
+
+func (x *logIndex) statLocked(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if x.files == nil {
+		x.files = make(map[string]logFileInfo)
+	}
+	x.files[path] = logFileInfo{path: path, modTime: info.ModTime(), size: info.Size()}
+}
+
+// 返回需要参与清理的日志文件，索引为空或超过校对间隔时遍历目录重建索引，调用方需持有 x.mutex
+func (l *Log) indexedLogFiles(dir string) ([]logFileInfo, error) {
+	x := &l.index
+	now := l.clockNow()
+	if x.scanned.IsZero() || now.Sub(x.scanned) >= indexReconcileInterval {
+		if err := l.rebuildIndex(dir); err != nil {
+			return nil, err
+		}
+		x.scanned = now
+	}
+	logFiles := make([]logFileInfo, 0, len(x.files)+len(x.live))
+	for _, file := range x.files {
+		logFiles = append(logFiles, file)
+	}
+	for path := range x.live {
+		if _, ok := x.files[path]; ok {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		logFiles = append(logFiles, logFileInfo{path: path, modTime: info.ModTime(), size: info.Size()})
+	}
+	return logFiles, nil
+}
+
+// 遍历目录重建已关闭文件的索引，正在写入的文件仍按 live 处理，调用方需持有 x.mutex
+func (l *Log) rebuildIndex(dir string) error {
+	x := &l.index
+	atomic.AddInt64(&x.walks, 1)
+	files := make(map[string]logFileInfo)
+	err := filepath.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// 检查文件是否为目录
+		if info.IsDir() || !l.isLogFile(info) || x.live[path] {
+			return nil
+		}
+		files[path] = logFileInfo{path: path, modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	x.files = files
+	return nil
+}
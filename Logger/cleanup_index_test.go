@@ -0,0 +1,75 @@
+package Logger
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLog_CleanupUsesIndex(t *testing.T) {
+	dir := t.TempDir()
+	paths := createDummyLogs(t, dir, 3, 1)
+	LogClient := NewLogger()
+	LogClient.SetMaxSize(200)
+	LogClient.SetMaxBackups(2)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	l := LogClient.(*Log)
+	for round := 0; round < 5; round++ {
+		for i := 0; i < 10; i++ {
+			LogClient.Infof("indexed cleanup message %d-%d", round, i)
+		}
+		LogClient.Flush()
+		l.runCleanup()
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected cleanup to keep 2 files, got %d", len(entries))
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected expired %s to be removed", path)
+		}
+	}
+	cleanups, walks := atomic.LoadInt64(&l.cleanups), atomic.LoadInt64(&l.index.walks)
+	if walks != 1 || cleanups < 5 {
+		t.Errorf("cleanup ran %d times with %d directory walks, want a single walk", cleanups, walks)
+	}
+}
+
+func TestLog_CleanupReconcilesIndex(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	l := LogClient.(*Log)
+	l.runCleanup()
+
+	// 外部放入的过期文件在校对前不在索引中，校对后被清理
+	old := time.Now().AddDate(0, 0, -10)
+	path := filepath.Join(dir, formatLogFileName(old))
+	if err := os.WriteFile(path, []byte("old\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	l.runCleanup()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected the unindexed file to survive until reconcile: %v", err)
+	}
+	// 回拨上次遍历的时间，模拟到达校对间隔
+	l.index.mutex.Lock()
+	l.index.scanned = l.index.scanned.Add(-indexReconcileInterval)
+	l.index.mutex.Unlock()
+	l.runCleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected reconcile to remove %s", path)
+	}
+}
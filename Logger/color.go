@@ -0,0 +1,47 @@
+package Logger
+
+import (
+	"os"
+	"strings"
+)
+
+// 控制台输出中各级别使用的 ANSI 颜色
+var levelColors = map[int]string{
+	Debug: "\x1b[90m",
+	Info:  "\x1b[32m",
+	Warn:  "\x1b[33m",
+	Error: "\x1b[31m",
+}
+
+const colorReset = "\x1b[0m"
+
+// 设置控制台输出是否按级别着色，只影响控制台，不影响文件，标准错误不是终端时自动关闭
+func (l *Log) SetColor(enable bool) {
+	l.Color = enable
+}
+
+// 设置标准错误不是终端时是否仍然着色，需同时开启 Color
+func (l *Log) SetForceColor(force bool) {
+	l.ForceColor = force
+}
+
+// 控制台输出的日志行，开启着色时为文本格式的级别标记加上颜色
+func (l *Log) consoleLine(level int, logline string) string {
+	if !l.Color || (!l.ForceColor && !isTerminal(os.Stderr)) {
+		return logline
+	}
+	token := "[" + l.GetLevelString(level) + "]"
+	color, ok := levelColors[level]
+	start := strings.Index(logline, token)
+	// 级别标记前只可能有 ShowSequence 添加的序号
+	if !ok || start < 0 || (start > 0 && !strings.HasPrefix(logline, "seq:")) {
+		return logline
+	}
+	return logline[:start] + color + token + colorReset + logline[start+len(token):]
+}
+
+// 判断文件是否为终端
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,48 @@
+package Logger
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLog_Color(t *testing.T) {
+	for _, force := range []bool{true, false} {
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stderr := os.Stderr
+		os.Stderr = writer
+
+		dir := t.TempDir()
+		LogClient := NewLogger()
+		LogClient.SetConsoleOutput(true)
+		LogClient.SetColor(true)
+		LogClient.SetForceColor(force)
+		LogClient.SetLogger(Info, dir, 6)
+		LogClient.Warnf("colored warning")
+		LogClient.Errorf("colored error")
+		content := readTodayLog(t, LogClient, dir)
+		LogClient.Close()
+		os.Stderr = stderr
+		_ = writer.Close()
+		console, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(content, "\x1b[") {
+			t.Errorf("file should never contain color codes: %q", content)
+		}
+		colored := strings.HasPrefix(string(console), "\x1b[33m[Warn]\x1b[0m[") &&
+			strings.Contains(string(console), "\n\x1b[31m[Error]\x1b[0m[")
+		if force && !colored {
+			t.Errorf("expected colored level tokens on the console, got %q", console)
+		}
+		if !force && strings.Contains(string(console), "\x1b[") {
+			t.Errorf("color should be disabled when stderr is not a terminal, got %q", console)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package Logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// 压缩指定日期的所有日志文件（包括按大小切分的文件）
+func (l *Log) compressLogsOfDate(date string) {
+	pattern := l.fileNamePattern()
+	matches, err := filepath.Glob(filepath.Join(l.logDir(), pattern.Prefix+date+"*"+pattern.Suffix))
+	if err != nil {
+		l.internalError("find logs to compress", err)
+		return
+	}
+	for _, path := range matches {
+		if err = compressFile(path, l.filePerm()); err != nil {
+			l.internalError("compress log", err)
+			continue
+		}
+		l.index.compressed(path)
+	}
+}
+
+// 切换文件后在后台压缩刚关闭的文件
+func (l *Log) compressRotated(path string) {
+	l.compressWg.Add(1)
+	go func() {
+		defer l.compressWg.Done()
+		if err := compressFile(path, l.filePerm()); err != nil {
+			l.internalError("compress log", err)
+			return
+		}
+		l.index.compressed(path)
+	}()
+}
+
+// 将 path 压缩为 path.gz 并保留原文件的修改时间，使按时间清理的逻辑不受影响，压缩成功后删除原文件
+func compressFile(path string, perm os.FileMode) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	gz.Name = filepath.Base(path)
+	gz.ModTime = info.ModTime()
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = gz.Close()
+		_ = dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	if err = os.Chtimes(path+".gz", info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,97 @@
+package Logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLog_CompressOnDayRollover(t *testing.T) {
+	dir := t.TempDir()
+	yesterday := time.Now().AddDate(0, 0, -1)
+	oldPath := filepath.Join(dir, formatLogFileName(yesterday))
+	if err := os.WriteFile(oldPath, []byte("yesterday line\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	LogClient := NewLogger()
+	LogClient.SetCompress(true)
+	LogClient.SetLogger(Info, dir, 6)
+	// 模拟当前文件属于前一天，下一次写入时触发跨天切换
+	LogClient.(*Log).currentDate = yesterday.Format("2006-01-02")
+	LogClient.Infof("today line")
+	LogClient.Close()
+
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("old log should be removed after compression: %v", err)
+	}
+	file, err := os.Open(oldPath + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "yesterday line\n" {
+		t.Errorf("unexpected decompressed content: %q", data)
+	}
+}
+
+func TestLog_CompressOnRotate(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetMaxSize(200)
+	LogClient.SetCompressOnRotate(true)
+	LogClient.SetLogger(Info, dir, 6)
+	LogClient.Infof("first file line")
+	LogClient.Flush()
+	firstPath := filepath.Join(dir, formatLogFileName(time.Now()))
+	original, err := os.ReadFile(firstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	if err = os.Chtimes(firstPath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	// 下一行会超出 MaxSize，写入前切换到新文件
+	LogClient.Infof("second file line %s", strings.Repeat("x", 100))
+	LogClient.Close()
+
+	if _, err = os.Stat(firstPath); !os.IsNotExist(err) {
+		t.Errorf("rotated log should be removed after compression: %v", err)
+	}
+	info, err := os.Stat(firstPath + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("gz mtime = %v, want %v", info.ModTime(), modTime)
+	}
+	file, err := os.Open(firstPath + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(original) {
+		t.Errorf("decompressed content = %q, want %q", data, original)
+	}
+}
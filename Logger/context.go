@@ -0,0 +1,48 @@
+package Logger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// 日志从 context 中读取的 key 类型，避免与其他包的 key 冲突
+type ContextKey string
+
+const (
+	TraceIDKey   ContextKey = "trace_id"
+	RequestIDKey ContextKey = "request_id"
+)
+
+// 按固定顺序从 context 中提取的 key
+var contextKeys = []ContextKey{TraceIDKey, RequestIDKey}
+
+func (l *Log) InfofCtx(ctx context.Context, format string, a ...interface{}) {
+	l.syncWriteLog(Info, nil, 0, contextPrefix(ctx)+format, a...)
+}
+
+func (l *Log) ErrorfCtx(ctx context.Context, format string, a ...interface{}) {
+	l.syncWriteLog(Error, nil, 0, contextPrefix(ctx)+format, a...)
+}
+
+func (d *derivedLogger) InfofCtx(ctx context.Context, format string, a ...interface{}) {
+	d.syncWriteLog(Info, &d.ctx, 0, contextPrefix(ctx)+format, a...)
+}
+
+func (d *derivedLogger) ErrorfCtx(ctx context.Context, format string, a ...interface{}) {
+	d.syncWriteLog(Error, &d.ctx, 0, contextPrefix(ctx)+format, a...)
+}
+
+// 将 context 中携带的值渲染为 "key=value " 前缀，% 会被转义以免影响格式化
+func contextPrefix(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	var builder strings.Builder
+	for _, key := range contextKeys {
+		if value := ctx.Value(key); value != nil {
+			builder.WriteString(fmt.Sprintf("%s=%v ", key, value))
+		}
+	}
+	return strings.ReplaceAll(builder.String(), "%", "%%")
+}
@@ -0,0 +1,25 @@
+package Logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLog_InfofCtx(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	ctx := context.WithValue(context.Background(), TraceIDKey, "trace-100%")
+	LogClient.InfofCtx(ctx, "ctx message %d", 1)
+	LogClient.ErrorfCtx(context.Background(), "plain message %d", 2)
+
+	content := readTodayLog(t, LogClient, dir)
+	if !strings.Contains(content, "message:trace_id=trace-100% ctx message 1\n") {
+		t.Errorf("trace id missing: %q", content)
+	}
+	if !strings.Contains(content, "funcName:TestLog_InfofCtx;message:plain message 2\n") {
+		t.Errorf("empty context should not change the message: %q", content)
+	}
+}
@@ -0,0 +1,55 @@
+package Logger
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// 开启连续重复消息的合并，级别、内容、名称、标签和字段都相同才视为重复，重复的消息不再写入，
+// 在出现不同消息或 timeout 到期时按被合并消息的级别输出重复次数，
+// 在 SetLogger 之前调用生效，timeout 为 0 时关闭
+func (l *Log) SetDedup(timeout time.Duration) {
+	l.DedupTimeout = timeout
+}
+
+// 判断是否与上一条日志的级别、消息内容、名称、标签和字段都相同，相同时计入重复次数，
+// 不同时先输出上一条的重复次数，再记下这一条
+func (l *Log) isRepeated(entry logLine) bool {
+	if l.dedupLast.text != "" && entry.level == l.dedupLast.level && entry.message == l.dedupLast.message &&
+		sameContext(entry.ctx, l.dedupLast.ctx) {
+		l.dedupCount++
+		return true
+	}
+	l.writeRepeated()
+	l.dedupLast = entry
+	return false
+}
+
+// 两条日志的名称、标签和字段是否相同
+func sameContext(a, b *logContext) bool {
+	if a == b {
+		return true
+	}
+	var empty logContext
+	if a == nil {
+		a = &empty
+	}
+	if b == nil {
+		b = &empty
+	}
+	if a.name != b.name || a.tags != b.tags || len(a.fields) != len(b.fields) {
+		return false
+	}
+	return len(a.fields) == 0 || reflect.DeepEqual(a.fields, b.fields)
+}
+
+// 输出上一条日志被合并的重复次数，级别、名称、标签和字段与上一条相同
+func (l *Log) writeRepeated() {
+	if l.dedupCount == 0 {
+		return
+	}
+	msg := fmt.Sprintf("last message repeated %d times", l.dedupCount)
+	l.dedupCount = 0
+	l.writeLine(l.newLogLine(l.dedupLast.level, l.dedupLast.ctx, nil, msg))
+}
@@ -0,0 +1,115 @@
+package Logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLog_SetDedup(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetDedup(time.Hour)
+	LogClient.SetLogger(Info, dir, 6)
+	for i := 0; i < 100; i++ {
+		LogClient.Errorf("repeated message")
+	}
+	LogClient.Infof("different message")
+	LogClient.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3: %q", len(lines), data)
+	}
+	if !strings.HasSuffix(lines[0], "message:repeated message") ||
+		!strings.HasSuffix(lines[1], "message:last message repeated 99 times") ||
+		!strings.HasSuffix(lines[2], "message:different message") {
+		t.Errorf("unexpected lines: %q", lines)
+	}
+}
+
+func TestLog_SetDedupTimeout(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetDedup(20 * time.Millisecond)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	for i := 0; i < 3; i++ {
+		LogClient.Infof("repeated message")
+	}
+
+	path := filepath.Join(dir, formatLogFileName(time.Now()))
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, _ := os.ReadFile(path)
+		if strings.Contains(string(data), "last message repeated 2 times") {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("repeat summary not written after timeout: %q", data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLog_DedupSummaryKeepsLevel(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetDedup(time.Hour)
+	LogClient.SetLogger(Info, dir, 6)
+	db := LogClient.Named("db")
+	for i := 0; i < 3; i++ {
+		db.Errorf("repeated message")
+	}
+	LogClient.Infof("different message")
+	LogClient.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3: %q", len(lines), data)
+	}
+	// 重复次数按被合并的那条日志的级别和名称输出，而不是下一条日志的
+	if !strings.HasPrefix(lines[1], "[Error]") || !strings.Contains(lines[1], "db") ||
+		!strings.HasSuffix(lines[1], "last message repeated 2 times") {
+		t.Errorf("unexpected summary line: %q", lines[1])
+	}
+}
+
+func TestLog_DedupDistinguishesContext(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetDedup(time.Hour)
+	LogClient.SetLogger(Info, dir, 6)
+	LogClient.Named("db").Errorf("same message")
+	LogClient.Named("api").Errorf("same message")
+	LogClient.WithFields(map[string]interface{}{"id": 1}).Errorf("same message")
+	LogClient.WithFields(map[string]interface{}{"id": 2}).Errorf("same message")
+	LogClient.WithFields(map[string]interface{}{"id": 2}).Errorf("same message")
+	LogClient.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want 5: %q", len(lines), data)
+	}
+	if !strings.Contains(lines[0], "db") || !strings.Contains(lines[1], "api") ||
+		!strings.Contains(lines[2], "id=1") || !strings.Contains(lines[3], "id=2") {
+		t.Errorf("lines from different loggers were merged: %q", lines)
+	}
+	if !strings.Contains(lines[4], "last message repeated 1 times") || !strings.Contains(lines[4], "id=2") {
+		t.Errorf("unexpected summary line: %q", lines[4])
+	}
+}
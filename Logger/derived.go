@@ -0,0 +1,136 @@
+package Logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 派生日志附加在每一行上的上下文
+type logContext struct {
+	name   string                 // 组件名，多级之间用 "." 连接
+	tags   string                 // 标签，多个 key=value 之间以空格分隔，按添加顺序输出
+	fields map[string]interface{} // 结构化字段
+}
+
+// 派生日志，与原日志共用同一个写入通道和文件。ctx 创建后不再修改，
+// 字段只在派生时复制一次，因此同一个派生日志可以在多个协程间共享，也可以并发地继续派生
+type derivedLogger struct {
+	*Log
+	ctx logContext
+}
+
+// 返回携带 fields 的派生日志，字段会附加在每一行日志之后，fields 会被复制，之后修改不影响派生日志
+func (l *Log) WithFields(fields map[string]interface{}) Logger {
+	return &derivedLogger{Log: l, ctx: logContext{fields: mergeFields(nil, fields)}}
+}
+
+// 返回带组件名的派生日志，组件名以 [name] 的形式输出在每一行日志中
+func (l *Log) Named(name string) Logger {
+	return &derivedLogger{Log: l, ctx: logContext{name: name}}
+}
+
+// 返回以 error 和 error_type 字段携带 err 的派生日志，err 为 nil 时返回原日志
+func (l *Log) WithError(err error) Logger {
+	if err == nil {
+		return l
+	}
+	return l.WithFields(errorFields(err))
+}
+
+// 返回携带 key=value 标签的派生日志，标签输出在消息之前，比字段少一次 map 的复制和排序
+func (l *Log) WithTag(key, value string) Logger {
+	return &derivedLogger{Log: l, ctx: logContext{tags: key + "=" + value}}
+}
+
+// 在已有字段的基础上合并新的字段，同名字段以新值为准
+func (d *derivedLogger) WithFields(fields map[string]interface{}) Logger {
+	ctx := d.ctx
+	ctx.fields = mergeFields(d.ctx.fields, fields)
+	return &derivedLogger{Log: d.Log, ctx: ctx}
+}
+
+// 在已有组件名之后追加 name，如 db 派生出 db.pool，字段 map 只读，新旧派生日志共用同一个
+func (d *derivedLogger) Named(name string) Logger {
+	ctx := d.ctx
+	if ctx.name != "" {
+		name = ctx.name + "." + name
+	}
+	ctx.name = name
+	return &derivedLogger{Log: d.Log, ctx: ctx}
+}
+
+// 在已有字段的基础上附加 error 和 error_type 字段，err 为 nil 时返回原日志
+func (d *derivedLogger) WithError(err error) Logger {
+	if err == nil {
+		return d
+	}
+	return d.WithFields(errorFields(err))
+}
+
+// 在已有标签之后追加 key=value，字段 map 只读，新旧派生日志共用同一个
+func (d *derivedLogger) WithTag(key, value string) Logger {
+	ctx := d.ctx
+	tag := key + "=" + value
+	if ctx.tags != "" {
+		tag = ctx.tags + " " + tag
+	}
+	ctx.tags = tag
+	return &derivedLogger{Log: d.Log, ctx: ctx}
+}
+
+func (d *derivedLogger) Errorf(format string, a ...interface{}) {
+	d.syncWriteLog(Error, &d.ctx, 0, format, a...)
+}
+
+func (d *derivedLogger) Fatalf(format string, a ...interface{}) {
+	d.syncWriteLog(Error, &d.ctx, 0, format, a...)
+	d.Flush()
+	exitFunc(1)
+}
+
+func (d *derivedLogger) Warnf(format string, a ...interface{}) {
+	d.syncWriteLog(Warn, &d.ctx, 0, format, a...)
+}
+
+func (d *derivedLogger) Infof(format string, a ...interface{}) {
+	d.syncWriteLog(Info, &d.ctx, 0, format, a...)
+}
+
+func (d *derivedLogger) Debugf(format string, a ...interface{}) {
+	d.syncWriteLog(Debug, &d.ctx, 0, format, a...)
+}
+
+// 错误对应的字段：错误信息和具体类型
+func errorFields(err error) map[string]interface{} {
+	return map[string]interface{}{"error": err.Error(), "error_type": fmt.Sprintf("%T", err)}
+}
+
+// 复制 base 并合并 fields，避免派生日志之间共享同一个 map
+func mergeFields(base, fields map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(fields))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return merged
+}
+
+// 文本格式下将字段按 key 排序后渲染为 " key=value" 形式
+func formatFields(fields map[string]interface{}) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var builder strings.Builder
+	for _, k := range keys {
+		builder.WriteString(fmt.Sprintf(" %s=%v", k, fields[k]))
+	}
+	return builder.String()
+}
@@ -0,0 +1,162 @@
+package Logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLog_WithFields(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.WithFields(map[string]interface{}{"request_id": "abc"}).
+		WithFields(map[string]interface{}{"user": 7}).
+		Infof("fields message")
+
+	content := readTodayLog(t, LogClient, dir)
+	if !strings.Contains(content, "message:fields message request_id=abc user=7\n") {
+		t.Errorf("fields missing: %q", content)
+	}
+}
+
+func TestLog_WithFieldsJSON(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetFormat(FormatJSON)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.WithFields(map[string]interface{}{"request_id": "abc"}).
+		WithFields(map[string]interface{}{"user": 7}).
+		Infof("fields message")
+
+	content := readTodayLog(t, LogClient, dir)
+	var entry struct {
+		Fields map[string]interface{} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(content), &entry); err != nil {
+		t.Fatalf("line is not valid JSON: %v: %q", err, content)
+	}
+	if entry.Fields["request_id"] != "abc" || entry.Fields["user"] != float64(7) {
+		t.Errorf("unexpected fields: %v", entry.Fields)
+	}
+}
+
+func TestLog_WithError(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetFormat(FormatJSON)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	_, err := os.Open(filepath.Join(dir, "missing"))
+	LogClient.WithError(err).Errorf("open failed")
+	if LogClient.WithError(nil) != LogClient {
+		t.Error("expected a nil error to return the same logger")
+	}
+	LogClient.Named("db").WithError(nil).Infof("no error")
+
+	content := readTodayLog(t, LogClient, dir)
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", content)
+	}
+	var entry struct {
+		Fields map[string]interface{} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Fields["error"] != err.Error() || entry.Fields["error_type"] != "*fs.PathError" {
+		t.Errorf("unexpected error fields: %v", entry.Fields)
+	}
+	if strings.Contains(lines[1], `"fields"`) {
+		t.Errorf("nil error should not add fields: %q", lines[1])
+	}
+}
+
+func TestLog_Named(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	db := LogClient.Named("db")
+	db.Infof("db message")
+	db.Named("pool").WithFields(map[string]interface{}{"size": 4}).Infof("pool message")
+
+	content := readTodayLog(t, LogClient, dir)
+	if !strings.Contains(content, "][db] fileLine:") {
+		t.Errorf("component tag missing: %q", content)
+	}
+	if !strings.Contains(content, "][db.pool] fileLine:") || !strings.Contains(content, "pool message size=4\n") {
+		t.Errorf("composed component tag missing: %q", content)
+	}
+}
+
+func TestLog_DeriveConcurrently(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	base := LogClient.WithFields(map[string]interface{}{"shared": 1}).Named("base")
+	const workers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fields := map[string]interface{}{"worker": i}
+			derived := base.WithFields(fields).Named(fmt.Sprintf("w%d", i))
+			// 派生后修改传入的 map 不应影响派生日志
+			fields["worker"] = -1
+			derived.Infof("worker message %d", i)
+			base.Infof("base message %d", i)
+		}(i)
+	}
+	wg.Wait()
+
+	content := readTodayLog(t, LogClient, dir)
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(lines) != 2*workers {
+		t.Fatalf("expected %d lines, got %d", 2*workers, len(lines))
+	}
+	for _, line := range lines {
+		var i int
+		if _, err := fmt.Sscanf(line[strings.Index(line, "message:"):], "message:worker message %d", &i); err == nil {
+			want := fmt.Sprintf("[base.w%d] ", i)
+			if !strings.Contains(line, want) || !strings.HasSuffix(line, fmt.Sprintf(" shared=1 worker=%d", i)) {
+				t.Errorf("fields crossed between derived loggers: %q", line)
+			}
+			continue
+		}
+		if !strings.Contains(line, "[base] ") || !strings.HasSuffix(line, " shared=1") {
+			t.Errorf("base logger picked up derived fields: %q", line)
+		}
+	}
+}
+
+func TestLog_WithTag(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	tenant := LogClient.WithTag("tenant", "acme")
+	tenant.WithTag("user", "42").Infof("tagged")
+	tenant.Named("db").Warnf("tenant only")
+
+	content := readTodayLog(t, LogClient, dir)
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", content)
+	}
+	if !strings.Contains(lines[0], "] tenant=acme user=42 fileLine:") {
+		t.Errorf("expected both tags in order, got %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "[db] tenant=acme fileLine:") || strings.Contains(lines[1], "user=42") {
+		t.Errorf("expected only the tenant tag after the name, got %q", lines[1])
+	}
+}
@@ -0,0 +1,86 @@
+package Logger
+
+import (
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// 预先组装好的一条日志，由 WriteEntry 直接写入，不经过 fmt.Sprintf，也是 Formatter 的输入
+type Entry struct {
+	Level   int                    // 日志级别
+	Time    time.Time              // 日志时间，为零值时使用当前时间
+	Message string                 // 日志内容，原样输出
+	Fields  map[string]interface{} // 结构化字段
+
+	// 以下字段在交给 Formatter 前由日志填充，WriteEntry 时设置的值会被忽略
+	Name      string // 日志名称，由 Named 设置
+	Tags      string // 标签，由 WithTag 设置，多个 key=value 之间以空格分隔
+	File      string // 调用处文件，为空表示不输出调用处信息
+	Line      int    // 调用处行号
+	Func      string // 调用处函数名
+	Goroutine uint64 // 协程 ID，开启 ShowGoroutineID 时才有值
+}
+
+// 写入一条已组装好的日志，与 Infof 等方法共用同一输出流程，但不调用 fmt.Sprintf
+func (l *Log) WriteEntry(e Entry) {
+	l.writeEntry(e, nil, 0, 0)
+}
+
+// 写入一条已组装好的日志，Entry 中的字段与派生日志的字段合并，同名字段以 Entry 为准
+func (d *derivedLogger) WriteEntry(e Entry) {
+	d.writeEntry(e, &d.ctx, 0, 0)
+}
+
+// 从 writeEntry 到用户调用处的栈深度：writeEntry -> WriteEntry -> 调用者
+const entryCallerDepth = 2
+
+// pc 不为 0 时以 pc 对应的位置作为调用处，否则查找 WriteEntry 的调用者
+func (l *Log) writeEntry(e Entry, ctx *logContext, pc uintptr, skip int) {
+	if e.Level < l.Level() {
+		return
+	}
+	if !l.limiter.allow(e.Level, l.clockNow()) {
+		return
+	}
+	e.Message = l.truncateMessage(e.Message)
+	entryCtx := &logContext{fields: e.Fields}
+	if ctx != nil {
+		entryCtx.name, entryCtx.tags = ctx.name, ctx.tags
+		if len(e.Fields) == 0 {
+			entryCtx.fields = ctx.fields
+		} else if len(ctx.fields) > 0 {
+			entryCtx.fields = mergeFields(ctx.fields, e.Fields)
+		}
+	}
+	t := e.Time
+	if t.IsZero() {
+		t = l.now()
+	} else if l.UseUTC {
+		t = t.UTC()
+	}
+	var caller *callerInfo
+	if pc != 0 {
+		caller = l.callerFromPC(pc)
+	} else {
+		caller = l.lookupCaller(entryCallerDepth + skip)
+	}
+	text := l.formatEntryAt(t, e.Level, entryCtx, caller, e.Message)
+	l.enqueue(logLine{level: e.Level, message: e.Message, ctx: entryCtx, text: text, time: t})
+}
+
+// 由程序计数器得到调用处信息，开启 DisableCaller 时返回 nil
+func (l *Log) callerFromPC(pc uintptr) *callerInfo {
+	if l.DisableCaller {
+		return nil
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.Function == "" {
+		return &callerInfo{file: unknownCaller, line: 0, funcName: unknownCaller}
+	}
+	file := frame.File
+	if l.ShortCaller {
+		file = filepath.Base(file)
+	}
+	return &callerInfo{file: file, line: frame.Line, funcName: l.funcName(frame.Function)}
+}
@@ -0,0 +1,78 @@
+package Logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLog_WriteEntry(t *testing.T) {
+	dir := t.TempDir()
+	fixed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	LogClient := NewLogger()
+	LogClient.(*Log).setClock(func() time.Time { return fixed })
+	LogClient.SetLogger(Info, dir, 0)
+	defer LogClient.Close()
+	fields := map[string]interface{}{"user": "alice", "id": 7}
+	LogClient.WithFields(fields).Infof("entry message 100%%")
+	LogClient.WriteEntry(Entry{Level: Info, Time: fixed, Message: "entry message 100%", Fields: fields})
+	LogClient.WriteEntry(Entry{Level: Debug, Message: "filtered by level"})
+
+	LogClient.Flush()
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(fixed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", content)
+	}
+	// 去掉调用处的行号后两行应完全一致
+	for i, line := range lines {
+		start := strings.Index(line, "entry_test.go:")
+		end := start + strings.Index(line[start:], " ")
+		lines[i] = line[:start] + line[end:]
+	}
+	if lines[0] != lines[1] {
+		t.Errorf("WriteEntry rendered %q, Infof rendered %q", lines[1], lines[0])
+	}
+	if !strings.Contains(lines[1], "funcName:TestLog_WriteEntry;message:entry message 100% id=7 user=alice") {
+		t.Errorf("unexpected entry line %q", lines[1])
+	}
+}
+
+func TestLog_BackdatedEntryStaysInCurrentFile(t *testing.T) {
+	dir := t.TempDir()
+	fixed := time.Date(2024, 3, 2, 0, 0, 1, 0, time.Local)
+	LogClient := NewLogger()
+	LogClient.(*Log).setClock(func() time.Time { return fixed })
+	LogClient.SetCompress(true)
+	LogClient.SetLogger(Info, dir, 0)
+	LogClient.Infof("today")
+	LogClient.WriteEntry(Entry{Level: Info, Time: fixed.Add(-2 * time.Second), Message: "yesterday"})
+	LogClient.Infof("today again")
+	LogClient.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(fixed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"today", "yesterday", "today again"} {
+		if !strings.Contains(string(data), "message:"+want+"\n") {
+			t.Errorf("expected %q in the current file, got %q", want, data)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the current file, got %v", entries)
+	}
+	if rotations := LogClient.Stats().Rotations; rotations != 0 {
+		t.Errorf("expected no rotation for a back-dated entry, got %d", rotations)
+	}
+}
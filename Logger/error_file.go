@@ -0,0 +1,69 @@
+package Logger
+
+import (
+	"os"
+	"time"
+)
+
+// 单独的错误日志文件名前缀，如 error-2006-01-02.log
+const errorFilePrefix = "error-"
+
+// 开启后 Error 级别的日志除写入普通日志文件外，还会写入单独的错误日志文件，在 SetLogger 之前调用生效
+func (l *Log) SetSeparateErrorFile(enable bool) {
+	l.SeparateErrorFile = enable
+}
+
+// 开启后 Error 和 Fatal 级别的日志写入后立即刷新缓冲并调用 Sync，保证进程崩溃前已落盘，其他级别仍按原方式缓冲写入
+func (l *Log) SetSyncOnError(enable bool) {
+	l.SyncOnError = enable
+}
+
+// 错误日志文件名格式，在普通日志文件名前加上 errorFilePrefix
+func (l *Log) errorFileNamePattern() FileNamePattern {
+	pattern := l.fileNamePattern()
+	pattern.Prefix = errorFilePrefix + pattern.Prefix
+	return pattern
+}
+
+// 将 Error 级别的日志写入错误日志文件，跨过切换周期时打开新文件
+func (l *Log) writeErrorLine(now time.Time, logline string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	pattern := l.errorFileNamePattern()
+	if date := pattern.date(now); l.errorFile == nil || date != l.errorDate {
+		if l.errorFile != nil {
+			_ = l.errorFile.Close()
+			l.index.retire(l.errorFile.Name())
+			l.errorFile = nil
+		}
+		File, err := l.openLogFile(pattern.fileName(now, 0))
+		if err != nil {
+			l.internalError("open error log file", err)
+			return
+		}
+		l.errorFile = File
+		l.errorDate = date
+		l.index.watch(File.Name())
+	}
+	if _, err := l.errorFile.WriteString(logline); err != nil {
+		l.writeFailed(err)
+	}
+}
+
+// 关闭错误日志文件，调用方需持有锁
+func (l *Log) closeErrorFile() error {
+	if l.errorFile == nil {
+		return nil
+	}
+	err := l.errorFile.Close()
+	l.errorFile = nil
+	return err
+}
+
+// 判断文件是否为需要清理的日志文件，包括错误日志文件。current.log 等软链接不是日志文件，不参与保留策略
+func (l *Log) isLogFile(info os.FileInfo) bool {
+	if info.Mode()&os.ModeSymlink != 0 || info.Name() == currentSymlinkName {
+		return false
+	}
+	return l.fileNamePattern().match(info.Name()) || l.errorFileNamePattern().match(info.Name())
+}
@@ -0,0 +1,88 @@
+package Logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLog_SeparateErrorFile(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetSeparateErrorFile(true)
+	LogClient.SetLogger(Info, dir, 6)
+	LogClient.Infof("info line")
+	LogClient.Errorf("error line")
+	content := readTodayLog(t, LogClient, dir)
+	if err := LogClient.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(content, "info line") || !strings.Contains(content, "error line") {
+		t.Errorf("expected both lines in the main log, got %q", content)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, errorFilePrefix+formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "error line") || strings.Contains(string(data), "info line") {
+		t.Errorf("unexpected error file content %q", data)
+	}
+}
+
+func TestLog_SeparateErrorFileCleanup(t *testing.T) {
+	dir := t.TempDir()
+	pattern := FileNamePattern{Prefix: "app-"}
+	oldPath := filepath.Join(dir, errorFilePrefix+"app-"+time.Now().AddDate(0, 0, -10).Format("2006-01-02")+".log")
+	if err := os.WriteFile(oldPath, []byte("old error\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	oldTime := time.Now().AddDate(0, 0, -10)
+	if err := os.Chtimes(oldPath, oldTime, oldTime); err != nil {
+		t.Fatal(err)
+	}
+	l := &Log{FilePath: dir, MaxDay: 3, FileNamePattern: pattern}
+	if err := l.clearOldLogs(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("expected old error log to be removed: %v", err)
+	}
+}
+
+// 等待写入协程处理完通道中的日志，但不像 Flush 那样刷新缓冲
+func waitWriterIdle(l *Log) {
+	l.pendingMutex.Lock()
+	defer l.pendingMutex.Unlock()
+	for l.pending > 0 {
+		l.pendingCond.Wait()
+	}
+}
+
+func TestLog_SyncOnError(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetFlushInterval(time.Hour)
+	LogClient.SetSyncOnError(true)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	path := filepath.Join(dir, formatLogFileName(time.Now()))
+
+	LogClient.Infof("buffered info")
+	waitWriterIdle(LogClient.(*Log))
+	if data, err := os.ReadFile(path); err != nil || len(data) != 0 {
+		t.Fatalf("expected info to stay buffered, got %q, %v", data, err)
+	}
+
+	LogClient.Errorf("critical error")
+	waitWriterIdle(LogClient.(*Log))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "message:buffered info\n") || !strings.Contains(string(data), "message:critical error\n") {
+		t.Errorf("expected the error to be on disk before Close, got %q", data)
+	}
+}
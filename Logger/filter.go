@@ -0,0 +1,16 @@
+package Logger
+
+// 设置过滤函数，返回 false 的日志在写入协程中被丢弃，fn 为 nil 时取消过滤，可在任意时刻调用
+func (l *Log) SetFilter(fn func(level int, msg string) bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.filter = fn
+}
+
+// 判断日志是否通过过滤函数，msg 为格式化后、组装成日志行之前的消息内容
+func (l *Log) passFilter(level int, msg string) bool {
+	l.mutex.Lock()
+	filter := l.filter
+	l.mutex.Unlock()
+	return filter == nil || filter(level, msg)
+}
@@ -0,0 +1,33 @@
+package Logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLog_SetFilter(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.SetFilter(func(level int, msg string) bool {
+		return !strings.Contains(msg, "healthz")
+	})
+	LogClient.Infof("GET /healthz 200")
+	LogClient.Infof("GET /api/users 200")
+	LogClient.Errorf("GET /healthz 500")
+
+	content := readTodayLog(t, LogClient, dir)
+	if strings.Contains(content, "healthz") {
+		t.Errorf("filtered entries reached the file: %q", content)
+	}
+	if !strings.Contains(content, "GET /api/users 200") {
+		t.Errorf("expected unfiltered entry, got %q", content)
+	}
+
+	LogClient.SetFilter(nil)
+	LogClient.Infof("GET /healthz 200")
+	if content = readTodayLog(t, LogClient, dir); !strings.Contains(content, "healthz") {
+		t.Errorf("expected entries after clearing the filter, got %q", content)
+	}
+}
@@ -0,0 +1,128 @@
+package Logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 将一条日志组装成写入的字节，返回值需包含行结束符
+type Formatter interface {
+	Format(e Entry) []byte
+}
+
+// 默认的文本格式：[级别][时间][名称] 标签 fileLine:文件:行号 funcName:函数;message:消息 字段
+type TextFormatter struct {
+	TimeFormat     string // 时间戳格式，为空时使用 defaultTimeFormat
+	LineTerminator string // 行结束符，为空时使用 defaultLineTerminator
+	LevelStyle     int    // 日志级别名称的写法，默认为 LevelStyleFull
+}
+
+func (f TextFormatter) Format(e Entry) []byte {
+	header := "[" + levelToken(e.Level, f.LevelStyle) + "][" + formatTimestamp(e.Time, f.TimeFormat) + "]"
+	if e.Name != "" {
+		header += "[" + e.Name + "]"
+	}
+	if e.Tags != "" {
+		header += " " + e.Tags
+	}
+	if e.Goroutine != 0 {
+		header += " goroutine:" + strconv.FormatUint(e.Goroutine, 10)
+	}
+	// 文本格式下转义消息中的换行，保证每条日志只占一个物理行
+	message := escapeNewlines(e.Message + formatFields(e.Fields))
+	terminator := terminatorOrDefault(f.LineTerminator)
+	if e.File == "" {
+		return []byte(fmt.Sprintf("%s message:%s%s", header, message, terminator))
+	}
+	return []byte(fmt.Sprintf("%s fileLine:%s:%d funcName:%s;message:%s%s", header, e.File, e.Line, e.Func, message, terminator))
+}
+
+// 每行一个 JSON 对象的格式
+type JSONFormatter struct {
+	TimeFormat     string // 时间戳格式，为空时使用 defaultTimeFormat
+	LineTerminator string // 行结束符，为空时使用 defaultLineTerminator
+	LevelStyle     int    // 日志级别名称的写法，默认为 LevelStyleFull
+}
+
+// JSON 格式下每行日志的结构
+type jsonLine struct {
+	Level     string                 `json:"level"`
+	Time      string                 `json:"time"`
+	Component string                 `json:"component,omitempty"`
+	Tags      string                 `json:"tags,omitempty"`
+	Goroutine uint64                 `json:"goroutine,omitempty"`
+	File      string                 `json:"file,omitempty"`
+	Line      int                    `json:"line,omitempty"`
+	Func      string                 `json:"func,omitempty"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+// 字段无法序列化时退回文本格式
+func (f JSONFormatter) Format(e Entry) []byte {
+	data, err := json.Marshal(jsonLine{
+		Level:     levelToken(e.Level, f.LevelStyle),
+		Time:      formatTimestamp(e.Time, f.TimeFormat),
+		Component: e.Name,
+		Tags:      e.Tags,
+		Goroutine: e.Goroutine,
+		File:      e.File,
+		Line:      e.Line,
+		Func:      e.Func,
+		Message:   e.Message,
+		Fields:    e.Fields,
+	})
+	if err != nil {
+		return TextFormatter(f).Format(e)
+	}
+	return append(data, terminatorOrDefault(f.LineTerminator)...)
+}
+
+// 设置自定义格式化器，设置后 Format、TimeFormat 和 LineTerminator 不再生效，传入 nil 恢复内置格式
+func (l *Log) SetFormatter(f Formatter) {
+	l.Formatter = f
+}
+
+// 当前使用的格式化器，未设置 Formatter 时按 Format 选择内置格式化器
+func (l *Log) formatter() Formatter {
+	if l.Formatter != nil {
+		return l.Formatter
+	}
+	if l.Format == FormatJSON {
+		return JSONFormatter{TimeFormat: l.TimeFormat, LineTerminator: l.LineTerminator, LevelStyle: l.LevelStyle}
+	}
+	return TextFormatter{TimeFormat: l.TimeFormat, LineTerminator: l.LineTerminator, LevelStyle: l.LevelStyle}
+}
+
+// 按布局格式化时间戳，布局为空时使用 defaultTimeFormat
+func formatTimestamp(t time.Time, layout string) string {
+	switch layout {
+	case "":
+		return t.Format(defaultTimeFormat)
+	case UnixTimeFormat:
+		return strconv.FormatInt(t.Unix(), 10)
+	default:
+		return t.Format(layout)
+	}
+}
+
+// 行结束符为空时使用 defaultLineTerminator
+func terminatorOrDefault(terminator string) string {
+	if terminator == "" {
+		return defaultLineTerminator
+	}
+	return terminator
+}
+
+var newlineEscaper = strings.NewReplacer("\r", `\r`, "\n", `\n`)
+
+// 将换行和回车转义为字面的 \n 和 \r
+func escapeNewlines(s string) string {
+	if !strings.ContainsAny(s, "\r\n") {
+		return s
+	}
+	return newlineEscaper.Replace(s)
+}
@@ -0,0 +1,63 @@
+package Logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 只输出级别和消息的自定义格式化器
+type pipeFormatter struct{}
+
+func (pipeFormatter) Format(e Entry) []byte {
+	return []byte(LevelString(e.Level) + "|" + e.Name + "|" + e.Message + "\n")
+}
+
+func TestLog_SetFormatter(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetFormatter(pipeFormatter{})
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("custom %d", 1)
+	LogClient.Named("db").Errorf("custom %d", 2)
+
+	content := readTodayLog(t, LogClient, dir)
+	if content != "Info||custom 1\nError|db|custom 2\n" {
+		t.Errorf("expected exactly the formatter output, got %q", content)
+	}
+}
+
+func TestTextFormatter(t *testing.T) {
+	e := Entry{
+		Level:   Warn,
+		Time:    time.Date(2024, 3, 5, 6, 7, 8, 0, time.UTC),
+		Message: "disk\nfull",
+		Fields:  map[string]interface{}{"used": 99},
+		Name:    "disk",
+		File:    "main.go",
+		Line:    12,
+		Func:    "check",
+	}
+	got := string(TextFormatter{LineTerminator: "\r\n"}.Format(e))
+	want := "[Warn][2024-03-05 06:07:08][disk] fileLine:main.go:12 funcName:check;message:disk\\nfull used=99\r\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestJSONFormatter(t *testing.T) {
+	e := Entry{Level: Info, Time: time.Unix(1700000000, 0), Message: "json message", Fields: map[string]interface{}{"k": "v"}}
+	data := JSONFormatter{TimeFormat: UnixTimeFormat}.Format(e)
+	if !strings.HasSuffix(string(data), "}\n") {
+		t.Fatalf("expected a newline terminated object, got %q", data)
+	}
+	var line jsonLine
+	if err := json.Unmarshal(data, &line); err != nil {
+		t.Fatal(err)
+	}
+	if line.Level != "Info" || line.Time != "1700000000" || line.Message != "json message" || line.Fields["k"] != "v" || line.File != "" {
+		t.Errorf("unexpected JSON line: %+v", line)
+	}
+}
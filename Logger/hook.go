@@ -0,0 +1,37 @@
+package Logger
+
+import (
+	"fmt"
+	"os"
+)
+
+// 日志钩子，每条写入的日志都会调用一次 Fire
+type Hook interface {
+	Fire(level int, msg string) error
+}
+
+// 添加钩子，钩子在写入协程中按添加顺序调用。与 SetOnRotate 的回调相同，Fire 中不能调用 Flush、Rotate、Reopen、
+// SetPath、Close 等需要等待写入协程的方法，写日志也只在通道未满时不会阻塞
+func (l *Log) AddHook(h Hook) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.hooks = append(l.hooks, h)
+}
+
+// 依次调用钩子，钩子返回的错误交给 SetInternalErrorHandler 设置的处理函数，未设置时输出到标准错误，不影响日志写入
+func (l *Log) fireHooks(level int, msg string) {
+	l.mutex.Lock()
+	hooks := l.hooks
+	l.mutex.Unlock()
+	for _, h := range hooks {
+		err := h.Fire(level, msg)
+		if err == nil {
+			continue
+		}
+		if fn := l.getInternalErrorHandler(); fn != nil {
+			fn(fmt.Errorf("fire log hook: %w", err))
+		} else {
+			fmt.Fprintln(os.Stderr, "Failed to fire log hook:", err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package Logger
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// 按级别统计触发次数的钩子
+type countingHook struct {
+	mutex  sync.Mutex
+	counts map[int]int
+	err    error
+}
+
+func (h *countingHook) Fire(level int, msg string) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.counts[level]++
+	return h.err
+}
+
+func TestLog_AddHook(t *testing.T) {
+	dir := t.TempDir()
+	hook := &countingHook{counts: map[int]int{}}
+	failing := &countingHook{counts: map[int]int{}, err: errors.New("hook failed")}
+	LogClient := NewLogger()
+	LogClient.SetLogger(Warn, dir, 6)
+	defer LogClient.Close()
+	LogClient.AddHook(failing)
+	LogClient.AddHook(hook)
+	LogClient.Infof("filtered message")
+	LogClient.Warnf("warn message")
+	LogClient.Errorf("error message 1")
+	LogClient.Errorf("error message 2")
+
+	content := readTodayLog(t, LogClient, dir)
+	if strings.Count(content, "\n") != 3 {
+		t.Errorf("hook errors should not stop writing: %q", content)
+	}
+	hook.mutex.Lock()
+	defer hook.mutex.Unlock()
+	if hook.counts[Info] != 0 || hook.counts[Warn] != 1 || hook.counts[Error] != 2 {
+		t.Errorf("unexpected hook counts: %v", hook.counts)
+	}
+}
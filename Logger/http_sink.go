@@ -0,0 +1,170 @@
+package Logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTP 远程输出的配置
+type HTTPSinkConfig struct {
+	URL           string        // 接收日志的地址
+	FlushInterval time.Duration // 定时发送的间隔，默认 1 秒
+	MaxBatchSize  int           // 每批最多发送的行数，默认 100
+	MaxRetries    int           // 发送失败后的最大重试次数，默认 3，小于 0 表示不重试
+	RetryBackoff  time.Duration // 首次重试前的等待时间，之后每次翻倍，默认 100 毫秒
+	Client        *http.Client  // 发送请求使用的客户端，默认使用超时为 10 秒的客户端
+}
+
+// 未指定 Client 时单次请求的超时时间，避免远程无响应时发送协程一直卡住
+const defaultHTTPSinkTimeout = 10 * time.Second
+
+// 发送到远程的单行日志
+type httpLine struct {
+	Level string `json:"level"`
+	Line  string `json:"line"`
+}
+
+// 按批将日志以 NDJSON 格式 POST 到远程地址，发送在独立协程中进行，不阻塞写入协程
+type httpSink struct {
+	cfg    HTTPSinkConfig
+	lines  chan []byte
+	wg     sync.WaitGroup
+	report func(op string, err error) // 报告发送失败
+	mutex  sync.Mutex                 // 保护 closed，保证关闭后不再向 lines 发送
+	closed bool                       // 已关闭，之后入队的日志被丢弃
+}
+
+// 设置 HTTP 远程输出，与文件输出同时生效
+func (l *Log) SetHTTPSink(cfg HTTPSinkConfig) {
+	sink := newHTTPSink(cfg, l.internalError)
+	l.mutex.Lock()
+	previous := l.httpSink
+	l.httpSink = sink
+	l.mutex.Unlock()
+	if previous != nil {
+		previous.close()
+	}
+}
+
+func (l *Log) getHTTPSink() *httpSink {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.httpSink
+}
+
+func newHTTPSink(cfg HTTPSinkConfig, report func(op string, err error)) *httpSink {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = 100
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 100 * time.Millisecond
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: defaultHTTPSinkTimeout}
+	}
+	s := &httpSink{cfg: cfg, lines: make(chan []byte, defaultBufferSize), report: report}
+	s.wg.Add(1)
+	go s.run()
+	return s
+}
+
+// 将一行日志放入发送队列，队列已满或已关闭时丢弃
+func (s *httpSink) enqueue(level, line string) {
+	data, err := json.Marshal(httpLine{Level: level, Line: strings.TrimRight(line, "\r\n\x00")})
+	if err != nil {
+		return
+	}
+	s.mutex.Lock()
+	full := false
+	if !s.closed {
+		select {
+		case s.lines <- data:
+		default:
+			full = true
+		}
+	}
+	s.mutex.Unlock()
+	if full {
+		s.report("ship logs", fmt.Errorf("http sink queue is full, dropping log line"))
+	}
+}
+
+func (s *httpSink) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+	var batch [][]byte
+	for {
+		select {
+		case data, ok := <-s.lines:
+			if !ok {
+				s.send(batch)
+				return
+			}
+			batch = append(batch, data)
+			if len(batch) >= s.cfg.MaxBatchSize {
+				s.send(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			s.send(batch)
+			batch = nil
+		}
+	}
+}
+
+// 发送一批日志，失败时按指数退避重试
+func (s *httpSink) send(batch [][]byte) {
+	if len(batch) == 0 {
+		return
+	}
+	body := append(bytes.Join(batch, []byte("\n")), '\n')
+	backoff := s.cfg.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= s.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = s.post(body); err == nil {
+			return
+		}
+	}
+	s.report("ship logs", err)
+}
+
+func (s *httpSink) post(body []byte) error {
+	resp, err := s.cfg.Client.Post(s.cfg.URL, "application/x-ndjson", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+// 发送剩余的日志后停止，重复调用是安全的
+func (s *httpSink) close() {
+	s.mutex.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.lines)
+	}
+	s.mutex.Unlock()
+	s.wg.Wait()
+}
@@ -0,0 +1,146 @@
+package Logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLog_HTTPSink(t *testing.T) {
+	var (
+		mutex    sync.Mutex
+		requests int
+		received []httpLine
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests++
+		// 第一次请求返回错误，验证会重试
+		if requests == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var line httpLine
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				t.Errorf("invalid NDJSON line %q: %v", scanner.Text(), err)
+			}
+			received = append(received, line)
+		}
+	}))
+	defer server.Close()
+
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, t.TempDir(), 6)
+	LogClient.SetHTTPSink(HTTPSinkConfig{
+		URL:           server.URL,
+		FlushInterval: time.Hour,
+		MaxBatchSize:  3,
+		RetryBackoff:  10 * time.Millisecond,
+	})
+	LogClient.Infof("remote message 1")
+	LogClient.Warnf("remote message 2")
+	LogClient.Errorf("remote message 3")
+	LogClient.Close()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2 (one failure and one retry)", requests)
+	}
+	if len(received) != 3 {
+		t.Fatalf("got %d lines, want 3: %+v", len(received), received)
+	}
+	if received[0].Level != "Info" || received[2].Level != "Error" {
+		t.Errorf("unexpected levels: %+v", received)
+	}
+}
+
+func TestLog_HTTPSinkReplacedWhileLogging(t *testing.T) {
+	// 不经过网络，直接返回成功，只验证替换发送端时的并发安全
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+	})}
+
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, t.TempDir(), 6)
+	LogClient.SetInternalErrorHandler(func(error) {})
+	cfg := HTTPSinkConfig{URL: "http://collector.invalid", FlushInterval: time.Hour, Client: client}
+	LogClient.SetHTTPSink(cfg)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				LogClient.Infof("remote message")
+			}
+		}
+	}()
+	// 替换发送端时写入协程可能仍持有旧的发送端，不应向已关闭的队列发送
+	for i := 0; i < 100; i++ {
+		LogClient.SetHTTPSink(cfg)
+	}
+	close(stop)
+	<-done
+	LogClient.Close()
+}
+
+func TestHTTPSink_EnqueueAfterClose(t *testing.T) {
+	sink := newHTTPSink(HTTPSinkConfig{URL: "http://127.0.0.1:0"}, func(string, error) {})
+	sink.close()
+	sink.enqueue("Info", "dropped")
+	sink.close()
+}
+
+func TestHTTPSink_DefaultClientTimeout(t *testing.T) {
+	sink := newHTTPSink(HTTPSinkConfig{URL: "http://127.0.0.1:0"}, func(string, error) {})
+	defer sink.close()
+	if sink.cfg.Client == http.DefaultClient || sink.cfg.Client.Timeout <= 0 {
+		t.Errorf("default client should have a timeout, got %v", sink.cfg.Client.Timeout)
+	}
+}
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestLog_CloseWithTimeoutStuckHTTPSink(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, t.TempDir(), 6)
+	LogClient.SetInternalErrorHandler(func(error) {})
+	LogClient.SetHTTPSink(HTTPSinkConfig{URL: server.URL, FlushInterval: time.Hour, MaxRetries: -1})
+	LogClient.Infof("unsent message")
+
+	start := time.Now()
+	if err := LogClient.CloseWithTimeout(100 * time.Millisecond); err == nil {
+		t.Error("expected timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("close took %v, want within deadline", elapsed)
+	}
+	// 远程恢复响应后，后台的关闭随之结束
+	close(release)
+	if err := LogClient.Close(); err != nil {
+		t.Errorf("Close after timeout = %v, want nil", err)
+	}
+}
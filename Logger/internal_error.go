@@ -0,0 +1,31 @@
+package Logger
+
+import (
+	"fmt"
+	"log"
+)
+
+// 保存在 atomic.Value 中的错误处理函数，atomic.Value 不能直接存 nil
+type internalErrorHandler struct {
+	fn func(error)
+}
+
+// 设置日志自身运行错误（清理、压缩、打开文件、写入、远程发送等失败）的处理函数，
+// 可用于接入监控告警，传入 nil 恢复默认行为，即输出到标准库 log
+func (l *Log) SetInternalErrorHandler(fn func(error)) {
+	l.errorHandler.Store(internalErrorHandler{fn: fn})
+}
+
+func (l *Log) getInternalErrorHandler() func(error) {
+	h, _ := l.errorHandler.Load().(internalErrorHandler)
+	return h.fn
+}
+
+// 报告日志自身的运行错误，不加锁，可在持有锁时调用
+func (l *Log) internalError(op string, err error) {
+	if fn := l.getInternalErrorHandler(); fn != nil {
+		fn(fmt.Errorf("%s: %w", op, err))
+		return
+	}
+	log.Printf("Failed to %s: %v\n", op, err)
+}
@@ -0,0 +1,33 @@
+package Logger
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLog_SetInternalErrorHandler(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	var mutex sync.Mutex
+	var got []error
+	LogClient.SetInternalErrorHandler(func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		got = append(got, err)
+	})
+	// 删除日志目录使清理时遍历目录失败
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	LogClient.(*Log).runCleanup()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(got) != 1 || !strings.HasPrefix(got[0].Error(), "clean old logs: ") {
+		t.Fatalf("expected the cleanup error to reach the handler, got %v", got)
+	}
+}
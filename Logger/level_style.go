@@ -0,0 +1,30 @@
+package Logger
+
+import "strings"
+
+// 日志级别名称的写法
+const (
+	LevelStyleFull  = iota // 首字母大写的完整名称，如 Info
+	LevelStyleUpper        // 全部大写，如 INFO
+	LevelStyleShort        // 只取首字母，如 I
+)
+
+// 设置日志级别名称的写法，LevelStyleFull、LevelStyleUpper 或 LevelStyleShort
+func (l *Log) SetLevelStyle(style int) {
+	l.LevelStyle = style
+}
+
+// 按写法输出日志级别的名称，无效的级别返回空字符串
+func levelToken(level, style int) string {
+	name := LevelString(level)
+	switch style {
+	case LevelStyleUpper:
+		return strings.ToUpper(name)
+	case LevelStyleShort:
+		if name == "" {
+			return ""
+		}
+		return name[:1]
+	}
+	return name
+}
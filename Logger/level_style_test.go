@@ -0,0 +1,57 @@
+package Logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLog_LevelStyle(t *testing.T) {
+	want := map[int][]string{
+		LevelStyleFull:  {"Debug", "Info", "Warn", "Error"},
+		LevelStyleUpper: {"DEBUG", "INFO", "WARN", "ERROR"},
+		LevelStyleShort: {"D", "I", "W", "E"},
+	}
+	for style, tokens := range want {
+		l := NewLogger().(*Log)
+		l.SetLevelStyle(style)
+		for i, level := range []int{Debug, Info, Warn, Error} {
+			if got := l.GetLevelString(level); got != tokens[i] {
+				t.Errorf("style %d: GetLevelString(%d) = %q, want %q", style, level, got, tokens[i])
+			}
+		}
+		if got := l.GetLevelString(0); got != "" {
+			t.Errorf("style %d: GetLevelString(0) = %q, want empty", style, got)
+		}
+	}
+}
+
+func TestLog_LevelStyleInFile(t *testing.T) {
+	for style, prefix := range map[int]string{
+		LevelStyleFull:  "[Warn][",
+		LevelStyleUpper: "[WARN][",
+		LevelStyleShort: "[W][",
+	} {
+		dir := t.TempDir()
+		LogClient := NewLogger()
+		LogClient.SetLevelStyle(style)
+		LogClient.SetLogger(Info, dir, 6)
+		LogClient.Warnf("styled level")
+		content := readTodayLog(t, LogClient, dir)
+		LogClient.Close()
+		if !strings.HasPrefix(content, prefix) {
+			t.Errorf("style %d: line = %q, want prefix %q", style, content, prefix)
+		}
+	}
+
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetFormat(FormatJSON)
+	LogClient.SetLevelStyle(LevelStyleUpper)
+	LogClient.SetLogger(Info, dir, 6)
+	LogClient.Errorf("styled json level")
+	content := readTodayLog(t, LogClient, dir)
+	LogClient.Close()
+	if !strings.HasPrefix(content, `{"level":"ERROR"`) {
+		t.Errorf("json line = %q, want upper-case level", content)
+	}
+}
@@ -1,248 +1,2023 @@
 package Logger
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
-	"io/fs"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 )
 
 type Logger interface {
-	SetLogger(Level int, FilePath string, MaxDay int64)
+	SetLogger(Level int, FilePath string, MaxDay int64) error
+	Debugf(format string, a ...interface{})
 	Errorf(format string, a ...interface{})
+	ErrorfWithStack(format string, a ...interface{})
+	RecoverAndLog(rethrow bool)
+	WriteEntry(e Entry)
+	Writer(level int) io.Writer
+	Fatalf(format string, a ...interface{})
+	Warnf(format string, a ...interface{})
 	Infof(format string, a ...interface{})
+	InfoRaw(msg string)
+	ErrorRaw(msg string)
+	InfofCtx(ctx context.Context, format string, a ...interface{})
+	ErrorfCtx(ctx context.Context, format string, a ...interface{})
+	SetMaxSize(MaxSize int64)
+	SetOutput(w io.Writer)
+	SetConsoleOutput(enable bool)
+	SetColor(enable bool)
+	SetForceColor(force bool)
+	SetCallerSkip(skip int)
+	SetShortCaller(enable bool)
+	SetFullFuncName(enable bool)
+	SetDisableCaller(disable bool)
+	SetSymlinkCurrent(enable bool)
+	SetShowGoroutineID(enable bool)
+	SetShowSequence(enable bool)
+	SetOnRotate(fn func(oldPath, newPath string))
+	SetFormat(format int)
+	SetFormatter(f Formatter)
+	WithFields(fields map[string]interface{}) Logger
+	Named(name string) Logger
+	WithError(err error) Logger
+	WithTag(key, value string) Logger
+	SetCompress(enable bool)
+	SetCompressOnRotate(enable bool)
+	SetSeparateErrorFile(enable bool)
+	SetSyncOnError(enable bool)
+	SetMaxBackups(MaxBackups int)
+	SetMaxTotalSize(MaxTotalSize int64)
+	SetPermissions(DirPerm, FilePerm os.FileMode)
+	SetTimeFormat(TimeFormat string)
+	SetLineTerminator(terminator string)
+	SetMaxMessageBytes(n int)
+	SetUseUTC(enable bool)
+	SetFileNamePattern(pattern FileNamePattern)
+	SetRotateInterval(interval int)
+	SetBackupNameScheme(scheme int)
+	SetLevelStyle(style int)
+	SetDropWhenFull(enable bool)
+	SetSendTimeout(timeout time.Duration)
+	SetBufferSize(size int)
+	SetFlushInterval(interval time.Duration)
+	SetSyslog(network, addr, tag string) error
+	SetHTTPSink(cfg HTTPSinkConfig)
+	AddHook(h Hook)
+	SetFilter(fn func(level int, msg string) bool)
+	SetRateLimit(level int, perSecond float64, burst int)
+	SetDedup(timeout time.Duration)
+	SetInternalErrorHandler(fn func(error))
+	Reopen() error
+	Rotate() error
+	SetPath(newPath string) error
+	DroppedCount() int64
+	Stats() Stats
+	ReadLast(n int) ([]string, error)
+	SetRingBufferSize(size int)
+	DumpRecent() []string
+	SetLevel(level int)
+	Level() int
+	IsLevelEnabled(level int) bool
 	GetConf()
-	Close()
+	GetConfig() Config
+	Flush()
+	Close() error
+	CloseWithTimeout(d time.Duration) error
 }
 
+// 从 logWithCallerInfo 到用户调用处的栈深度：
+// logWithCallerInfo -> syncWriteLog -> Infof 等公开方法 -> 调用者
+const callerDepth = 3
+
 const (
 	Debug = iota + 1
 	Info
+	Warn
 	Error
 )
 
+// 日志输出格式
+const (
+	FormatText = iota
+	FormatJSON
+)
+
+// 日志的配置项，只包含可以比较和复制的取值。Formatter、OnRotate、钩子、过滤器和 syslog、HTTP 等输出
+// 是接口、函数或需要连接的资源，不放在这里，创建后通过对应的 Set 方法设置
+type Config struct {
+	Level             int             // 日志级别
+	FilePath          string          // 文件存储路径
+	MaxDay            int64           // 最大存储天数，0 使用默认的 defaultMaxDay 天，负数表示永久保留，GetConfig 此时返回 -1
+	MaxSize           int64           // 单个文件最大字节数，0 表示不限制
+	MaxBackups        int             // 最多保留的日志文件个数，0 表示不限制
+	MaxTotalSize      int64           // 所有日志文件的总字节数上限，0 表示不限制
+	ConsoleOutput     bool            // 是否同时输出到控制台
+	CallerSkip        int             // 额外跳过的调用栈层数
+	Format            int             // 输出格式，FormatText 或 FormatJSON
+	Compress          bool            // 按天切换文件后是否压缩前一天的日志
+	DirPerm           os.FileMode     // 日志目录权限
+	FilePerm          os.FileMode     // 日志文件权限
+	TimeFormat        string          // 日志行中时间戳的格式
+	UseUTC            bool            // 时间戳和文件名是否使用 UTC 时间
+	FileNamePattern   FileNamePattern // 日志文件名格式
+	RotateInterval    int             // 切换文件的周期
+	DropWhenFull      bool            // 通道已满时是否丢弃消息
+	SendTimeout       time.Duration   // 通道已满时最多等待的时间，0 表示一直阻塞
+	BufferSize        int             // 异步写入通道的容量
+	FlushInterval     time.Duration   // 缓冲写入时定时刷新的间隔
+	DedupTimeout      time.Duration   // 合并连续重复消息的超时时间，0 表示不去重
+	ShortCaller       bool            // 调用处只输出文件名，不输出完整路径
+	DisableCaller     bool            // 不查找和输出调用处信息
+	SymlinkCurrent    bool            // 是否维护指向当前日志文件的 current.log 软链接
+	ShowGoroutineID   bool            // 是否在日志行中输出协程 ID
+	CompressOnRotate  bool            // 每次切换文件后是否立即压缩刚关闭的文件
+	SeparateErrorFile bool            // Error 级别的日志是否同时写入单独的错误日志文件
+	LineTerminator    string          // 每行日志的结束符，为空时使用 defaultLineTerminator
+	MaxMessageBytes   int             // 单条消息的最大字节数，超出时截断，0 表示不限制
+	Color             bool            // 控制台输出是否按级别着色
+	ForceColor        bool            // 标准错误不是终端时是否仍然着色
+	FullFuncName      bool            // 函数名是否包含完整的包路径和接收者
+	ShowSequence      bool            // 是否在每行日志前输出递增的序号
+	RingBufferSize    int             // 内存中保留的最近日志条数，0 表示不保留
+	SyncOnError       bool            // Error 级别的日志写入后是否立即同步到磁盘
+	BackupNameScheme  int             // 切分后旧文件的命名方式，BackupNameDated 或 BackupNameTimestamp
+	LevelStyle        int             // 日志级别名称的写法，LevelStyleFull、LevelStyleUpper 或 LevelStyleShort
+}
+
 type Log struct {
-	LogLevel    int         // 日志级别
-	FilePath    string      // 文件存储路径
-	MaxDay      int64       // 最大存储天数
-	currentFile *os.File    // 当前文件
-	currentDate string      // 文件创建时的日期
-	mutex       sync.Mutex  // 互斥锁
-	logChannels chan string // 异步写入
+	LogLevel          int32                            // 日志级别，通过 Level/SetLevel 原子读写
+	FilePath          string                           // 文件存储路径
+	MaxDay            int64                            // 最大存储天数，0 或负数表示永久保留，SetLogger 统一记为 -1
+	MaxSize           int64                            // 单个文件最大字节数，0 表示不限制
+	MaxBackups        int                              // 最多保留的日志文件个数，0 表示不限制
+	MaxTotalSize      int64                            // 所有日志文件的总字节数上限，0 表示不限制
+	ConsoleOutput     bool                             // 是否同时输出到控制台
+	Color             bool                             // 控制台输出是否按级别着色
+	ForceColor        bool                             // 标准错误不是终端时是否仍然着色
+	CallerSkip        int                              // 额外跳过的调用栈层数，用于封装日志方法的场景
+	Format            int                              // 输出格式，FormatText 或 FormatJSON
+	Formatter         Formatter                        // 自定义格式化器，为 nil 时按 Format 使用内置格式化器
+	Compress          bool                             // 按天切换文件后是否将前一天的日志压缩为 .log.gz
+	CompressOnRotate  bool                             // 每次切换文件后是否立即压缩刚关闭的文件
+	SeparateErrorFile bool                             // Error 级别的日志是否同时写入单独的错误日志文件
+	SyncOnError       bool                             // Error 级别的日志写入后是否立即同步到磁盘
+	DirPerm           os.FileMode                      // 日志目录权限，0 时使用 defaultDirPerm
+	FilePerm          os.FileMode                      // 日志文件权限，0 时使用 defaultFilePerm
+	TimeFormat        string                           // 日志行中时间戳的格式，为空时使用 defaultTimeFormat
+	LineTerminator    string                           // 每行日志的结束符，为空时使用 defaultLineTerminator
+	MaxMessageBytes   int                              // 单条消息的最大字节数，超出时截断，0 表示不限制
+	RingBufferSize    int                              // 内存中保留的最近日志条数，0 表示不保留
+	UseUTC            bool                             // 时间戳和文件名是否使用 UTC 时间
+	FileNamePattern   FileNamePattern                  // 日志文件名格式
+	RotateInterval    int                              // 切换文件的周期，RotateDaily、RotateHourly 或 RotateMonthly
+	BackupNameScheme  int                              // 切分后旧文件的命名方式，BackupNameDated 或 BackupNameTimestamp
+	LevelStyle        int                              // 日志级别名称的写法，LevelStyleFull、LevelStyleUpper 或 LevelStyleShort
+	DropWhenFull      bool                             // 通道已满时是否丢弃消息而不是阻塞
+	SendTimeout       time.Duration                    // 通道已满时最多等待的时间，0 表示一直阻塞
+	BufferSize        int                              // 异步写入通道的容量，0 时使用 defaultBufferSize
+	FlushInterval     time.Duration                    // 缓冲写入时定时刷新的间隔，0 表示不缓冲直接写入文件
+	DedupTimeout      time.Duration                    // 合并连续重复消息的超时时间，0 表示不去重
+	ShortCaller       bool                             // 调用处只输出文件名，不输出完整路径
+	FullFuncName      bool                             // 函数名是否包含完整的包路径和接收者
+	DisableCaller     bool                             // 不查找和输出调用处信息
+	SymlinkCurrent    bool                             // 是否维护指向当前日志文件的 current.log 软链接
+	ShowGoroutineID   bool                             // 是否在日志行中输出协程 ID
+	ShowSequence      bool                             // 是否在每行日志前输出递增的序号
+	OnRotate          func(oldPath, newPath string)    // 切换到新文件后调用的回调
+	currentFile       *os.File                         // 当前文件
+	errorFile         *os.File                         // 当前的错误日志文件，未开启 SeparateErrorFile 时为 nil
+	errorDate         string                           // 错误日志文件对应的日期
+	fileBuffer        *bufio.Writer                    // 当前文件的写缓冲，未开启缓冲时为 nil
+	currentDate       string                           // 文件创建时的日期
+	currentTime       time.Time                        // 当前文件打开时所用的时间，时间更早的日志仍写入当前文件
+	currentSize       int64                            // 当前文件已写入字节数
+	fileIndex         int                              // 当天按大小切分的文件序号
+	output            io.Writer                        // 自定义输出，设置后不再写入文件
+	stderrFallback    bool                             // output 是否为 SetLogger 之前默认使用的标准错误
+	openFailed        bool                             // 新文件多次打开失败后退回到标准错误，进入新周期或调用 Reopen、Rotate 时重新尝试打开，受 mutex 保护
+	mutex             sync.Mutex                       // 互斥锁
+	writerWg          sync.WaitGroup                   // 等待写入协程退出
+	writerRunning     bool                             // 写入协程是否已启动
+	closeMutex        sync.Mutex                       // 串行化 Close 和 CloseWithTimeout，重复或并发关闭时只有第一次关闭通道
+	compressWg        sync.WaitGroup                   // 等待后台压缩完成
+	pending           int                              // 已入队但尚未写入的日志条数
+	pendingMutex      sync.Mutex                       // 保护 pending
+	pendingCond       *sync.Cond                       // pending 归零时通知 Flush
+	ctx               context.Context                  // 构造时传入，取消后写入协程写完剩余日志并退出
+	writerStopped     bool                             // 写入协程是否已停止接收日志，ctx 取消或 Close 后为 true，受 pendingMutex 保护
+	sending           int                              // 已通过检查、正在向通道发送的调用数，关闭通道前需等待其归零，受 pendingMutex 保护
+	cleanups          int64                            // 清理执行次数
+	index             logIndex                         // 已知日志文件的索引，清理时使用
+	dropped           int64                            // 因通道已满丢弃的日志条数
+	linesWritten      int64                            // 已写入的日志行数
+	bytesWritten      int64                            // 已写入的字节数
+	rotations         int64                            // 切换文件的次数
+	fileWrites        int64                            // 写入文件的次数，批量写入时多条日志计一次
+	writeErrors       int64                            // 写入失败的次数
+	sequence          uint64                           // 已分配的最大日志序号，切换文件后继续递增
+	ring              *ringBuffer                      // 保存最近日志的环形缓冲区
+	errorHandler      atomic.Value                     // 日志自身运行错误的处理函数，保存 internalErrorHandler
+	lastWriteWarn     time.Time                        // 上次输出写入失败警告的时间，只在写入协程中访问
+	batch             []byte                           // 待批量写入文件的日志，只在写入协程中访问
+	batchLines        int                              // batch 中的日志行数，写入成功后才计入统计
+	lastFileCheck     time.Time                        // 上次检查当前文件是否已被删除的时间，只在写入协程中访问
+	fileChecks        int64                            // 检查文件是否已被删除的次数
+	clock             func() time.Time                 // 时间和日期的来源，为 nil 时使用 time.Now
+	cleanupNotify     chan struct{}                    // 通知清理协程立即执行一次清理
+	cleanupStop       chan struct{}                    // 关闭时停止清理协程
+	cleanupWg         sync.WaitGroup                   // 等待清理协程退出
+	syslogWriter      *syslogWriter                    // 系统日志输出，未设置时为 nil
+	httpSink          *httpSink                        // HTTP 远程输出，未设置时为 nil
+	hooks             []Hook                           // 每条日志写入时触发的钩子
+	filter            func(level int, msg string) bool // 过滤函数，返回 false 的日志被丢弃
+	limiter           rateLimiter                      // 按级别限流
+	dedupLast         logLine                          // 去重时最近写入的一条日志，只在写入协程中访问
+	dedupCount        int                              // dedupLast 之后被合并的重复次数
+	logChannels       chan logLine                     // 异步写入
+}
+
+// 默认的目录和文件权限
+const (
+	defaultDirPerm  os.FileMode = 0777
+	defaultFilePerm os.FileMode = 0666
+)
+
+// 指定默认日志级别的环境变量，取值为 debug/info/warn/error，不区分大小写
+const levelEnvVar = "LOG_LEVEL"
+
+// 默认日志级别，LOG_LEVEL 未设置或取值无效时为 Info
+func defaultLevel() int {
+	if level, ok := levelFromName(os.Getenv(levelEnvVar)); ok {
+		return level
+	}
+	return Info
+}
+
+// 将配置中的级别名称解析为日志级别，不区分大小写，无法识别的名称返回错误
+func ParseLevel(s string) (int, error) {
+	level, ok := levelFromName(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid log level: %q", s)
+	}
+	return level, nil
 }
 
+// 将级别名称解析为日志级别，不区分大小写
+func levelFromName(name string) (int, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return Debug, true
+	case "info":
+		return Info, true
+	case "warn", "warning":
+		return Warn, true
+	case "error":
+		return Error, true
+	}
+	return 0, false
+}
+
+// 异步写入通道的默认容量
+const defaultBufferSize = 3000
+
+// 每行日志默认的结束符
+const defaultLineTerminator = "\n"
+
+// 日志行中时间戳的默认格式
+const defaultTimeFormat = "2006-01-02 15:04:05"
+
+// TimeFormat 设为该值时时间戳输出为 Unix 秒数
+const UnixTimeFormat = "unix"
+
+// 带毫秒和微秒的时间戳格式，用于区分同一秒内的并发日志
+const (
+	MilliTimeFormat = "2006-01-02 15:04:05.000"
+	MicroTimeFormat = "2006-01-02 15:04:05.000000"
+)
+
+// Fatalf 写完日志后调用的退出函数，测试中可替换
+var exitFunc = os.Exit
+
+// 打开日志文件，测试中替换以模拟打开失败
+var openFile = os.OpenFile
+
+// 切换文件时打开文件的最大尝试次数，以及首次重试前的等待时间，之后每次翻倍
+const (
+	openAttempts = 4
+	openBackoff  = 10 * time.Millisecond
+)
+
+// 定时清理过期日志的间隔
+var cleanupInterval = time.Hour
+
+// 写入成功时检查当前文件是否已被删除的最小间隔，避免每次写入都多一次 os.Stat
+var fileCheckInterval = time.Second
+
+// 创建日志，调用 SetLogger 之前日志输出到标准错误
 func NewLogger() Logger {
 	Nlog := new(Log)
+	Nlog.InitLogger()
+	Nlog.output = os.Stderr
+	Nlog.stderrFallback = true
+	Nlog.startWriter()
+	return Nlog
+}
+
+// 创建日志，ctx 取消时写入协程写完已入队的日志并退出，之后的日志被丢弃，
+// 便于与服务已有的基于 context 的关闭流程配合
+func NewLoggerWithContext(ctx context.Context) Logger {
+	Nlog := new(Log)
+	Nlog.ctx = ctx
+	Nlog.InitLogger()
+	Nlog.output = os.Stderr
+	Nlog.stderrFallback = true
+	Nlog.startWriter()
 	return Nlog
 }
 
+// 未指定时日志的保留天数
+const defaultMaxDay = 7
+
+// 永久保留日志时 MaxDay 的取值
+const keepForever = -1
+
+// 按配置创建日志，校验配置并补全默认值，返回的日志可以直接使用
+func NewLoggerWithConfig(cfg Config) (Logger, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if cfg.MaxDay == 0 {
+		cfg.MaxDay = defaultMaxDay
+	}
+	Nlog := new(Log)
+	Nlog.MaxSize = cfg.MaxSize
+	Nlog.MaxBackups = cfg.MaxBackups
+	Nlog.MaxTotalSize = cfg.MaxTotalSize
+	Nlog.ConsoleOutput = cfg.ConsoleOutput
+	Nlog.CallerSkip = cfg.CallerSkip
+	Nlog.Format = cfg.Format
+	Nlog.Compress = cfg.Compress
+	Nlog.DirPerm = cfg.DirPerm
+	Nlog.FilePerm = cfg.FilePerm
+	Nlog.TimeFormat = cfg.TimeFormat
+	Nlog.UseUTC = cfg.UseUTC
+	Nlog.FileNamePattern = cfg.FileNamePattern
+	Nlog.RotateInterval = cfg.RotateInterval
+	Nlog.DropWhenFull = cfg.DropWhenFull
+	Nlog.SendTimeout = cfg.SendTimeout
+	Nlog.BufferSize = cfg.BufferSize
+	Nlog.FlushInterval = cfg.FlushInterval
+	Nlog.DedupTimeout = cfg.DedupTimeout
+	Nlog.ShortCaller = cfg.ShortCaller
+	Nlog.DisableCaller = cfg.DisableCaller
+	Nlog.SymlinkCurrent = cfg.SymlinkCurrent
+	Nlog.ShowGoroutineID = cfg.ShowGoroutineID
+	Nlog.CompressOnRotate = cfg.CompressOnRotate
+	Nlog.SeparateErrorFile = cfg.SeparateErrorFile
+	Nlog.LineTerminator = cfg.LineTerminator
+	Nlog.MaxMessageBytes = cfg.MaxMessageBytes
+	Nlog.Color = cfg.Color
+	Nlog.ForceColor = cfg.ForceColor
+	Nlog.FullFuncName = cfg.FullFuncName
+	Nlog.ShowSequence = cfg.ShowSequence
+	Nlog.SetRingBufferSize(cfg.RingBufferSize)
+	Nlog.SyncOnError = cfg.SyncOnError
+	Nlog.BackupNameScheme = cfg.BackupNameScheme
+	Nlog.LevelStyle = cfg.LevelStyle
+	if err := Nlog.SetLogger(cfg.Level, cfg.FilePath, cfg.MaxDay); err != nil {
+		return nil, err
+	}
+	return Nlog, nil
+}
+
+// 校验配置项的取值范围
+func (c Config) validate() error {
+	if c.Level < 0 || c.Level > Error {
+		return fmt.Errorf("invalid log level: %d", c.Level)
+	}
+	if c.MaxSize < 0 || c.MaxBackups < 0 || c.MaxTotalSize < 0 {
+		return fmt.Errorf("retention limits must not be negative")
+	}
+	if c.BufferSize < 0 {
+		return fmt.Errorf("invalid buffer size: %d", c.BufferSize)
+	}
+	if c.FlushInterval < 0 {
+		return fmt.Errorf("invalid flush interval: %v", c.FlushInterval)
+	}
+	if c.SendTimeout < 0 {
+		return fmt.Errorf("invalid send timeout: %v", c.SendTimeout)
+	}
+	if c.CallerSkip < 0 {
+		return fmt.Errorf("invalid caller skip: %d", c.CallerSkip)
+	}
+	if c.Format != FormatText && c.Format != FormatJSON {
+		return fmt.Errorf("invalid log format: %d", c.Format)
+	}
+	if c.RotateInterval < RotateDaily || c.RotateInterval > RotateMonthly {
+		return fmt.Errorf("invalid rotate interval: %d", c.RotateInterval)
+	}
+	if c.DedupTimeout < 0 {
+		return fmt.Errorf("invalid dedup timeout: %v", c.DedupTimeout)
+	}
+	if c.MaxMessageBytes < 0 {
+		return fmt.Errorf("invalid max message bytes: %d", c.MaxMessageBytes)
+	}
+	if c.RingBufferSize < 0 {
+		return fmt.Errorf("invalid ring buffer size: %d", c.RingBufferSize)
+	}
+	if c.BackupNameScheme != BackupNameDated && c.BackupNameScheme != BackupNameTimestamp {
+		return fmt.Errorf("invalid backup name scheme: %d", c.BackupNameScheme)
+	}
+	if c.LevelStyle < LevelStyleFull || c.LevelStyle > LevelStyleShort {
+		return fmt.Errorf("invalid level style: %d", c.LevelStyle)
+	}
+	return nil
+}
+
 func (l *Log) InitLogger() {
-	l.LogLevel = Info
-	l.MaxDay = 7
+	l.SetLevel(defaultLevel())
+	l.MaxDay = defaultMaxDay
 	l.FilePath = "."
-	l.logChannels = make(chan string, 3000)
+	l.logChannels = make(chan logLine, l.bufferSize())
+	l.pendingCond = sync.NewCond(&l.pendingMutex)
 }
 
-func (l *Log) SetLogger(Level int, FilePath string, MaxDay int64) {
+// 初始化日志，目录创建或文件打开失败时返回错误
+func (l *Log) SetLogger(Level int, FilePath string, MaxDay int64) error {
+	// 重复调用时先停止之前的写入协程和清理协程，并关闭之前打开的文件
+	l.stopWriter()
+	l.stopCleanup()
+	l.closeFiles()
 	l.InitLogger()
+	if l.stderrFallback {
+		l.output = nil
+		l.stderrFallback = false
+		l.openFailed = false
+	}
 	if Level != 0 {
-		switch Level {
-		case Debug:
-			l.LogLevel = Debug
-		case Info:
-			l.LogLevel = Info
-		case Error:
-			l.LogLevel = Error
-		}
+		l.SetLevel(Level)
 	}
 	if FilePath != "" {
 		// 确保日志文件目录存在
-		err := os.MkdirAll(FilePath, 0777)
+		err := os.MkdirAll(FilePath, l.dirPerm())
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 		l.FilePath = FilePath
 	}
-	l.FilePath = relativePathToAbsPath(l.FilePath)
+	absPath, err := relativePathToAbsPath(l.FilePath)
+	if err != nil {
+		return err
+	}
+	l.FilePath = absPath
+	// 0 和负数都表示永久保留，统一记为 -1，与 Config 中的表示一致
+	if MaxDay <= 0 {
+		MaxDay = keepForever
+	}
 	l.MaxDay = MaxDay
-	FileName := formatLogFileName(time.Now())
-	File, err := os.OpenFile(l.FilePath+"/"+FileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	if l.getOutput() != nil {
+		// 使用自定义输出时跳过文件创建和清理
+		l.startWriter()
+		return nil
+	}
+	now := l.now()
+	// 重启后继续追加到当前周期最新的文件，不重复创建已有序号的文件
+	l.fileIndex = l.latestFileIndex(now)
+	l.index.reset()
+	FileName := l.activeFileName(now, l.fileIndex)
+	File, err := l.openLogFile(FileName)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	l.currentFile = File
-	l.currentDate = formatLogFileName(time.Now())
+	l.setCurrentFile(File)
+	l.currentDate, l.currentTime = l.fileNamePattern().date(now), now
+	l.currentSize = fileSize(File)
 	// 清理日志文件
-	go func() {
-		err = l.clearOldLogs()
-		if err != nil {
-			log.Println("Failed to clean old logs:", err)
+	l.startCleanup()
+	l.startWriter()
+	return nil
+}
+
+// 停止已启动的写入协程，通道中的日志会先全部写完
+func (l *Log) stopWriter() {
+	if !l.writerRunning {
+		return
+	}
+	l.stopSending()
+	close(l.logChannels)
+	l.writerWg.Wait()
+	l.writerRunning = false
+}
+
+// 不再接收新的日志和操作，并等待正在发送的调用完成，之后关闭通道不会引起 panic
+func (l *Log) stopSending() {
+	l.pendingMutex.Lock()
+	defer l.pendingMutex.Unlock()
+	l.writerStopped = true
+	for l.sending > 0 {
+		l.pendingCond.Wait()
+	}
+}
+
+// 启动写入协程
+func (l *Log) startWriter() {
+	l.writerRunning = true
+	l.pendingMutex.Lock()
+	l.writerStopped = false
+	l.pendingMutex.Unlock()
+	var done <-chan struct{}
+	if l.ctx != nil {
+		done = l.ctx.Done()
+	}
+	l.writerWg.Add(1)
+	go l.logWriteToFile(l.FlushInterval, l.DedupTimeout, done)
+}
+
+// 记录一条待写入的日志，写入协程已退出时返回 false
+func (l *Log) addPending() bool {
+	l.pendingMutex.Lock()
+	defer l.pendingMutex.Unlock()
+	if l.writerStopped {
+		return false
+	}
+	l.pending++
+	return true
+}
+
+// 与 addPending 相同，并记录一个正在发送的调用，发送完成或放弃后需调用 doneSending
+func (l *Log) beginSend() bool {
+	l.pendingMutex.Lock()
+	defer l.pendingMutex.Unlock()
+	if l.writerStopped {
+		return false
+	}
+	l.pending++
+	l.sending++
+	return true
+}
+
+// 发送完成，通知等待关闭通道的 stopSending
+func (l *Log) doneSending() {
+	l.pendingMutex.Lock()
+	defer l.pendingMutex.Unlock()
+	l.sending--
+	if l.sending == 0 {
+		l.pendingCond.Broadcast()
+	}
+}
+
+func (l *Log) pendingCount() int {
+	l.pendingMutex.Lock()
+	defer l.pendingMutex.Unlock()
+	return l.pending
+}
+
+// ctx 取消后不再接收新日志，写完已入队的日志并刷新文件
+func (l *Log) drainOnCancel(dedupTimeout time.Duration) {
+	l.pendingMutex.Lock()
+	l.writerStopped = true
+	l.pendingMutex.Unlock()
+	for l.pendingCount() > 0 {
+		entry, ok := <-l.logChannels
+		if !ok {
+			break
 		}
-	}()
-	go l.logWriteToFile()
+		l.handleEntry(entry, dedupTimeout)
+		l.flushBatch()
+		l.donePending()
+	}
+	l.finishWriter()
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.flushFileBuffer()
+	if l.currentFile != nil {
+		_ = l.currentFile.Sync()
+	}
 }
 
-func (l *Log) logWriteToFile() {
-	for logline := range l.logChannels {
-		if logline != "" {
-			currentDate := time.Now().Format("2006-01-02")
-			if currentDate != l.currentDate {
-				l.createLogFile(time.Now())
-			}
-			_, _ = l.currentFile.WriteString(logline)
+// 写入协程退出前输出合并和限流的统计，并写出批量缓冲中的日志
+func (l *Log) finishWriter() {
+	l.writeRepeated()
+	l.writeSuppressed()
+	l.flushBatch()
+}
+
+// 处理通道中的一条日志，返回是否有重复消息被合并，调用方需在写出批量缓冲后调用 donePending
+func (l *Log) handleEntry(entry logLine, dedupTimeout time.Duration) bool {
+	if entry.action != nil {
+		// 操作可能切换文件，先写出之前的日志
+		l.flushBatch()
+		entry.action()
+		return false
+	}
+	if entry.text == "" || !l.passFilter(entry.level, entry.message) {
+		return false
+	}
+	if dedupTimeout > 0 && l.isRepeated(entry) {
+		return true
+	}
+	l.writeLine(entry)
+	return false
+}
 
-			// 检查并执行清理操作
-			go func() {
-				err := l.clearOldLogs()
-				if err != nil {
-					log.Println("Failed to clean old logs:", err)
+func (l *Log) logWriteToFile(flushInterval, dedupTimeout time.Duration, done <-chan struct{}) {
+	defer l.writerWg.Done()
+	// 开启缓冲写入时定时将缓冲区刷新到文件，未开启时 flushTick 为 nil 不会触发
+	var flushTick <-chan time.Time
+	if flushInterval > 0 {
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+		flushTick = ticker.C
+	}
+	// 定时输出被限流丢弃的日志条数
+	summaryTicker := time.NewTicker(rateSummaryInterval)
+	defer summaryTicker.Stop()
+	// 开启去重时，重复消息在超时后输出重复次数
+	var dedupTimer <-chan time.Time
+	for {
+		select {
+		case entry, ok := <-l.logChannels:
+			if !ok {
+				l.finishWriter()
+				return
+			}
+			repeated := l.handleEntry(entry, dedupTimeout)
+			// 通道中已有的日志一并处理，合并为一次文件写入
+			count, closed := 1, false
+		batch:
+			for count < maxBatchLines {
+				select {
+				case next, ok := <-l.logChannels:
+					if !ok {
+						closed = true
+						break batch
+					}
+					repeated = l.handleEntry(next, dedupTimeout) || repeated
+					count++
+				default:
+					break batch
 				}
-			}()
+			}
+			l.flushBatch()
+			l.donePendingN(count)
+			if closed {
+				l.finishWriter()
+				return
+			}
+			if repeated && dedupTimer == nil {
+				dedupTimer = time.After(dedupTimeout)
+			}
+		case <-done:
+			l.drainOnCancel(dedupTimeout)
+			return
+		case <-dedupTimer:
+			l.writeRepeated()
+			l.flushBatch()
+			dedupTimer = nil
+		case <-flushTick:
+			l.mutex.Lock()
+			l.flushFileBuffer()
+			l.mutex.Unlock()
+		case <-summaryTicker.C:
+			l.writeSuppressed()
+			l.flushBatch()
 		}
 	}
 }
 
-func (l *Log) syncWriteLog(format string, a ...interface{}) {
-	message := l.logWithCallerInfo(fmt.Sprintf(format, a...))
-	l.logChannels <- message
+// 按日志自身的时间而不是写入时的时间切换文件，通道中积压的日志仍写入所属周期的文件。
+// 只向后切换，时间早于当前文件的日志写入当前文件，不重新打开之前周期的文件
+func (l *Log) entryTime(entry logLine) time.Time {
+	now := entry.time
+	if now.IsZero() {
+		now = l.now()
+	}
+	if now.Before(l.currentTime) {
+		now = l.currentTime
+	}
+	return now
 }
 
-func (l *Log) createLogFile(date time.Time) {
+// 是否因打开文件失败而退回到标准错误
+func (l *Log) fileOpenFailed() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.openFailed
+}
+
+// 离开打开失败后的标准错误输出，之后由 createLogFile 重新打开文件
+func (l *Log) leaveFallback() {
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
+	l.output = nil
+	l.stderrFallback = false
+	l.openFailed = false
+}
+
+// 打开文件失败后立即重试，仍然失败时返回错误，Reopen 和 Rotate 使用
+func (l *Log) retryFileOpen() error {
+	l.leaveFallback()
+	now := l.now()
+	if l.fileNamePattern().date(now) != l.currentDate {
+		l.fileIndex = 0
+	}
+	l.createLogFile(now)
+	if l.fileOpenFailed() {
+		return fmt.Errorf("log file could not be opened, writing to stderr")
+	}
+	return nil
+}
+
+// 将单条日志写入输出目标，必要时先切换文件
+func (l *Log) writeLine(entry logLine) {
+	logline := entry.text
+	if l.ShowSequence {
+		logline = l.withSequence(logline)
+	}
+	if l.ConsoleOutput {
+		_, _ = os.Stderr.WriteString(l.consoleLine(entry.level, logline))
+	}
+	if sw := l.getSyslog(); sw != nil {
+		if err := sw.write(entry.level, logline); err != nil {
+			l.internalError("write syslog", err)
+		}
+	}
+	if sink := l.getHTTPSink(); sink != nil {
+		sink.enqueue(l.GetLevelString(entry.level), logline)
+	}
+	l.fireHooks(entry.level, logline)
+	if ring := l.getRing(); ring != nil {
+		ring.add(logline)
+	}
+	now := l.entryTime(entry)
+	// 打开文件失败退回到标准错误后，进入新的周期时重新尝试打开
+	if l.fileOpenFailed() && l.fileNamePattern().date(now) != l.currentDate {
+		l.leaveFallback()
+		l.fileIndex = 0
+		l.createLogFile(now)
+	}
+	if w := l.getOutput(); w != nil {
+		l.writeOutput(w, logline)
+		return
+	}
+	currentDate := l.fileNamePattern().date(now)
+	// 切换文件前先将批量缓冲写入旧文件
+	if currentDate != l.currentDate {
+		l.flushBatch()
+		l.fileIndex = 0
+		l.createLogFile(now)
+	}
+	// 超过单个文件大小限制时切分到下一个序号的文件
+	if l.MaxSize > 0 && l.currentSize > 0 && l.currentSize+int64(len(logline)) > l.MaxSize {
+		l.flushBatch()
+		l.fileIndex++
+		l.createLogFile(now)
+	}
+	// 新文件打开失败时已退回到标准错误
+	if w := l.getOutput(); w != nil {
+		l.writeOutput(w, logline)
+		return
+	}
+	l.batch = append(l.batch, logline...)
+	l.batchLines++
+	l.currentSize += int64(len(logline))
+	if l.SeparateErrorFile && entry.level >= Error {
+		l.writeErrorLine(now, logline)
+	}
+	if l.SyncOnError && entry.level >= Error {
+		l.flushBatch()
+		if err := l.syncFiles(); err != nil {
+			l.writeFailed(err)
+		}
+	}
+}
 
+// 将缓冲区写入文件并同步到磁盘，包括单独的错误日志文件
+func (l *Log) syncFiles() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if err := l.flushFileBuffer(); err != nil {
+		return err
+	}
 	if l.currentFile != nil {
-		_ = l.currentFile.Close()
+		if err := l.currentFile.Sync(); err != nil {
+			return err
+		}
+	}
+	if l.errorFile != nil {
+		return l.errorFile.Sync()
+	}
+	return nil
+}
+
+// 在写入协程中为日志行分配序号，保证序号与写入顺序一致，
+// JSON 行插入 seq 字段，其他格式在行首加上 seq:N
+func (l *Log) withSequence(logline string) string {
+	seq := strconv.FormatUint(atomic.AddUint64(&l.sequence, 1), 10)
+	if strings.HasPrefix(logline, "{") {
+		return `{"seq":` + seq + "," + logline[1:]
 	}
-	// 创建新文件
-	FileName := formatLogFileName(time.Now())
-	File, err := os.OpenFile(l.FilePath+"/"+FileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	return "seq:" + seq + " " + logline
+}
+
+// 写入自定义输出目标
+func (l *Log) writeOutput(w io.Writer, logline string) {
+	n, err := io.WriteString(w, logline)
 	if err != nil {
-		log.Fatal(err)
+		l.writeFailed(err)
 		return
 	}
-	l.currentFile = File
-	l.currentDate = date.Format("2006-01-02")
+	l.recordWrite(n)
 }
 
-func (l *Log) Errorf(format string, a ...interface{}) {
-	l.LogLevel = Error
-	l.syncWriteLog(format, a...)
+// 一次批量写入最多合并的日志条数
+const maxBatchLines = 256
+
+// 将批量缓冲中的日志一次写入当前文件，只在写入协程中调用
+func (l *Log) flushBatch() {
+	if len(l.batch) == 0 {
+		return
+	}
+	_, err := l.writeToFile(l.batch)
+	// Linux 下文件被删除后写入已打开的句柄仍会成功，因此写入成功时也定期检查文件是否还在
+	if err != nil || l.fileCheckDue() && l.fileMissing() {
+		if rerr := l.recoverFile(); rerr != nil {
+			err = fmt.Errorf("recover log file: %w", rerr)
+		} else {
+			_, err = l.writeToFile(l.batch)
+		}
+	}
+	if err != nil {
+		l.writeFailed(err)
+	} else {
+		l.recordWrites(l.batchLines, len(l.batch))
+	}
+	atomic.AddInt64(&l.fileWrites, 1)
+	l.batch = l.batch[:0]
+	l.batchLines = 0
 }
 
-func (l *Log) Infof(format string, a ...interface{}) {
-	l.LogLevel = Info
-	l.syncWriteLog(format, a...)
+// 距上次检查超过 fileCheckInterval 时返回 true 并记录本次检查的时间
+func (l *Log) fileCheckDue() bool {
+	now := l.clockNow()
+	if !l.lastFileCheck.IsZero() && now.Sub(l.lastFileCheck) < fileCheckInterval {
+		return false
+	}
+	l.lastFileCheck = now
+	atomic.AddInt64(&l.fileChecks, 1)
+	return true
 }
 
-func (l *Log) GetLevelString() string {
-	var Level string
-	switch l.LogLevel {
-	case Debug:
-		Level = "Debug"
-	case Info:
-		Level = "Info"
-	case Error:
-		Level = "Error"
+// 当前文件是否已被外部删除
+func (l *Log) fileMissing() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.currentFile == nil {
+		return false
 	}
-	return Level
+	_, err := os.Stat(l.currentFile.Name())
+	return os.IsNotExist(err)
 }
 
-func (l *Log) GetConf() {
-	var Level string
-	switch l.LogLevel {
-	case Debug:
-		Level = "Debug"
-	case Info:
-		Level = "Info"
-	case Error:
-		Level = "Error"
+// 重新创建日志目录并按原路径打开文件，只在写入协程中调用
+func (l *Log) recoverFile() error {
+	if err := os.MkdirAll(l.logDir(), l.dirPerm()); err != nil {
+		return err
 	}
-	fmt.Println(Level, l.FilePath, l.MaxDay)
+	return l.reopenFile()
 }
 
-func formatLogFileName(data time.Time) string {
-	return data.Format("2006-01-02") + ".log"
+// 通道中传递的单条日志
+type logLine struct {
+	level   int         // 日志级别
+	message string      // 格式化前的消息内容，用于去重比较
+	ctx     *logContext // 名称、标签和字段，用于去重比较和输出重复次数
+	text    string      // 格式化后的日志行
+	action  func()      // 不为 nil 时表示需要在写入协程中执行的操作
+	time    time.Time   // 日志的时间，在入队前格式化时确定，用于选择写入的文件
 }
 
-// 获取对应文件名，行号，方法名
-func (l *Log) logWithCallerInfo(logline string) string {
-	pc, file, line, _ := runtime.Caller(3)
-	funcName := runtime.FuncForPC(pc).Name()
-	Level := l.GetLevelString()
-	return fmt.Sprintf("[%s][%s] fileLine:%s:%d funcName:%s;message:%s\n", Level, time.Now().Format("2006-01-02 15:04:05"), file, line, getFunctionName(funcName), logline)
+// 在写入协程中执行 fn 并等待其返回，保证与之前入队的日志按顺序执行
+func (l *Log) runInWriter(fn func() error) error {
+	done := make(chan error, 1)
+	if !l.beginSend() {
+		return fmt.Errorf("log writer has stopped")
+	}
+	l.logChannels <- logLine{action: func() { done <- fn() }}
+	l.doneSending()
+	return <-done
 }
 
-// 获取对应的方法名
-func getFunctionName(fullName string) string {
-	// 获取函数名的最后一个点号后面的部分
-	lastDotIndex := 0
-	for i := len(fullName) - 1; i >= 0; i-- {
-		if fullName[i] == '.' {
-			lastDotIndex = i
-			break
-		}
+// 按日志级别过滤后写入通道，低于配置级别的消息直接丢弃
+func (l *Log) syncWriteLog(level int, ctx *logContext, skip int, format string, a ...interface{}) {
+	if level < l.Level() {
+		return
 	}
-	if fullName == "" {
-		return ""
+	if !l.limiter.allow(level, l.clockNow()) {
+		return
 	}
-	return fullName[lastDotIndex+1:]
+	msg := l.truncateMessage(formatMessage(format, a))
+	l.enqueue(l.logWithCallerInfo(level, ctx, skip, msg))
 }
 
-// 清除过期日志
-func (l *Log) clearOldLogs() error {
-	// 需要清除的日期范围
-	cutoffDate := time.Now().AddDate(0, 0, -int(l.MaxDay))
+// 没有参数且不含 % 时 fmt.Sprintf 的结果与 format 相同，直接返回以省去一次分配
+func formatMessage(format string, a []interface{}) string {
+	if len(a) == 0 && strings.IndexByte(format, '%') < 0 {
+		return format
+	}
+	return fmt.Sprintf(format, a...)
+}
 
-	err := filepath.Walk(l.FilePath, func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+// 消息超出 MaxMessageBytes 时追加的标记
+const truncatedMarker = "...[truncated]"
+
+// 消息超出 MaxMessageBytes 时截断并追加标记，截断位置不会落在多字节字符中间
+func (l *Log) truncateMessage(msg string) string {
+	if l.MaxMessageBytes <= 0 || len(msg) <= l.MaxMessageBytes {
+		return msg
+	}
+	cut := l.MaxMessageBytes
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	return msg[:cut] + truncatedMarker
+}
 
-		// 检查文件是否为目录
-		if info.IsDir() {
-			return nil
+// 将日志放入写入通道，开启 DropWhenFull 时通道已满则丢弃，设置了 SendTimeout 时等待超时后丢弃
+func (l *Log) enqueue(message logLine) {
+	// 关闭后不再接收日志，直接丢弃
+	if !l.beginSend() {
+		return
+	}
+	sent := l.send(message)
+	l.doneSending()
+	if !sent {
+		atomic.AddInt64(&l.dropped, 1)
+		l.donePending()
+	}
+}
 
+// 按 DropWhenFull 和 SendTimeout 向通道发送，返回是否已发送
+func (l *Log) send(message logLine) bool {
+	if !l.DropWhenFull && l.SendTimeout <= 0 {
+		l.logChannels <- message
+		return true
+	}
+	// 通道已满时丢弃消息并计数，不阻塞调用方
+	select {
+	case l.logChannels <- message:
+		return true
+	default:
+	}
+	if !l.DropWhenFull {
+		// 最多等待 SendTimeout，超时后再丢弃
+		timer := time.NewTimer(l.SendTimeout)
+		defer timer.Stop()
+		select {
+		case l.logChannels <- message:
+			return true
+		case <-timer.C:
 		}
-		// 检查文件日期是否早于需要清除的日期范围
-		if info.ModTime().Before(cutoffDate) {
-			// 删除文件
-			if strings.HasSuffix(path, ".log") {
-				if err = os.Remove(path); err != nil {
-					return err
-				}
-				log.Printf("Removed log file: %s\n", path)
-			}
+	}
+	return false
+}
+
+// 获取因通道已满而丢弃的日志条数
+func (l *Log) DroppedCount() int64 {
+	return atomic.LoadInt64(&l.dropped)
+}
+
+// 标记一条日志已处理完毕
+func (l *Log) donePending() {
+	l.donePendingN(1)
+}
+
+// 标记 n 条日志已处理完毕
+func (l *Log) donePendingN(n int) {
+	l.pendingMutex.Lock()
+	l.pending -= n
+	if l.pending == 0 {
+		l.pendingCond.Broadcast()
+	}
+	l.pendingMutex.Unlock()
+}
+
+// 阻塞直到通道中的日志全部写入，并将文件内容同步到磁盘
+func (l *Log) Flush() {
+	l.pendingMutex.Lock()
+	for l.pending > 0 {
+		l.pendingCond.Wait()
+	}
+	l.pendingMutex.Unlock()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.flushFileBuffer()
+	if l.currentFile != nil {
+		_ = l.currentFile.Sync()
+	}
+}
+
+// 关闭并按原路径重新打开当前文件，文件被外部工具移走后会创建新文件，可在收到 SIGHUP 时调用。
+// 打开文件失败退回到标准错误时重新尝试打开，仍然失败则返回错误
+func (l *Log) Reopen() error {
+	return l.runInWriter(func() error {
+		if l.fileOpenFailed() {
+			return l.retryFileOpen()
 		}
+		return l.reopenFile()
+	})
+}
 
+func (l *Log) reopenFile() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.currentFile == nil {
 		return nil
-	})
+	}
+	l.flushFileBuffer()
+	path := l.currentFile.Name()
+	_ = l.currentFile.Close()
+	// 文件可能已被外部移走或删除，下次清理时重新遍历目录
+	l.index.reset()
+	File, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, l.filePerm())
 	if err != nil {
-		return fmt.Errorf("failed to clear old logs:%v", err)
+		l.currentFile = nil
+		return err
 	}
+	l.setCurrentFile(File)
+	l.currentSize = fileSize(File)
 	return nil
 }
 
-func relativePathToAbsPath(Path string) string {
-	absolutePath, err := filepath.Abs(Path)
+// 立即切换到新文件，不等待日期变化或文件写满，旧文件按 BackupNameScheme 命名，如归档前调用。
+// 新文件打开失败或之前已退回到标准错误且重试仍失败时返回错误
+func (l *Log) Rotate() error {
+	return l.runInWriter(l.rotateNow)
+}
+
+// 在写入协程中执行，与日期和大小触发的切换走同一流程
+func (l *Log) rotateNow() error {
+	if l.fileOpenFailed() {
+		return l.retryFileOpen()
+	}
+	l.mutex.Lock()
+	open := l.currentFile != nil
+	l.mutex.Unlock()
+	if !open {
+		return nil
+	}
+	l.flushBatch()
+	now := l.now()
+	if l.fileNamePattern().date(now) != l.currentDate {
+		l.fileIndex = 0
+	} else {
+		l.fileIndex++
+	}
+	l.createLogFile(now)
+	if l.fileOpenFailed() {
+		return fmt.Errorf("log file could not be opened, writing to stderr")
+	}
+	return nil
+}
+
+// 在运行中切换日志目录，关闭当前文件并在新目录下打开文件，之后的清理也针对新目录
+func (l *Log) SetPath(newPath string) error {
+	if err := os.MkdirAll(newPath, l.dirPerm()); err != nil {
+		return err
+	}
+	newPath, err := relativePathToAbsPath(newPath)
 	if err != nil {
-		fmt.Println("Failed to get absolute path:", err)
-		return ""
+		return err
 	}
-	fmt.Println(absolutePath)
-	return absolutePath
+	return l.runInWriter(func() error {
+		return l.switchDir(newPath)
+	})
 }
 
-// 关闭对应的写入通道和文件
-func (l *Log) Close() {
-	close(l.logChannels)
+func (l *Log) switchDir(dir string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_ = l.closeErrorFile()
+	if l.currentFile == nil {
+		// 使用自定义输出时只记录新目录
+		l.FilePath = dir
+		return nil
+	}
+	now := l.now()
+	oldDir := l.FilePath
+	l.FilePath = dir
+	index := l.latestFileIndex(now)
+	File, err := l.openLogFile(l.activeFileName(now, index))
+	if err != nil {
+		l.FilePath = oldDir
+		return err
+	}
+	l.flushFileBuffer()
+	_ = l.currentFile.Close()
+	l.index.reset()
+	l.setCurrentFile(File)
+	l.fileIndex = index
+	l.currentDate, l.currentTime = l.fileNamePattern().date(now), now
+	l.currentSize = fileSize(File)
+	l.notifyCleanup()
+	return nil
+}
+
+// 写入当前文件，开启缓冲时先写入缓冲区
+func (l *Log) writeToFile(data []byte) (int, error) {
+	if l.fileBuffer == nil {
+		return l.currentFile.Write(data)
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.fileBuffer.Write(data)
+}
+
+// 切换当前文件，FlushInterval 大于 0 时为其创建写缓冲，调用方需持有锁或处于初始化阶段
+func (l *Log) setCurrentFile(File *os.File) {
+	l.currentFile = File
+	l.fileBuffer = nil
+	if l.FlushInterval > 0 {
+		l.fileBuffer = bufio.NewWriter(File)
+	}
+	if l.SymlinkCurrent {
+		l.updateSymlink(File.Name())
+	}
+	l.index.watch(File.Name())
+}
+
+// 指向当前日志文件的软链接名
+const currentSymlinkName = "current.log"
+
+// 先创建临时软链接再重命名覆盖 current.log，保证读取方始终看到完整的链接，
+// 不支持软链接的平台上创建失败时直接忽略
+func (l *Log) updateSymlink(target string) {
+	link := filepath.Join(filepath.Dir(target), currentSymlinkName)
+	tmp := link + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(target), tmp); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		_ = os.Remove(tmp)
+	}
+}
+
+// 将缓冲区内容写入文件，调用方需持有锁
+func (l *Log) flushFileBuffer() error {
+	if l.fileBuffer != nil {
+		return l.fileBuffer.Flush()
+	}
+	return nil
+}
+
+func (l *Log) createLogFile(date time.Time) {
+	oldPath, newPath := l.switchLogFile(date)
+	// 回调在锁外执行，不会因 l.mutex 死锁，但仍在写入协程中，限制见 SetOnRotate
+	if l.OnRotate != nil && newPath != "" {
+		l.OnRotate(oldPath, newPath)
+	}
+}
+
+// 关闭当前文件并打开新文件，返回旧文件和新文件的路径
+func (l *Log) switchLogFile(date time.Time) (oldPath, newPath string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.currentFile != nil {
+		oldPath = l.currentFile.Name()
+		l.flushFileBuffer()
+		_ = l.currentFile.Close()
+		if l.BackupNameScheme == BackupNameTimestamp {
+			backup, err := l.renameToBackup(oldPath, date)
+			if err != nil {
+				l.internalError("rename log backup", err)
+			} else {
+				oldPath = backup
+			}
+		}
+		l.index.retire(oldPath)
+	}
+	// 创建新文件，多次重试仍失败时退回到标准错误，不让进程退出
+	FileName := l.activeFileName(date, l.fileIndex)
+	File, err := l.openLogFileWithRetry(FileName)
+	if err != nil {
+		l.internalError("open log file", err)
+		l.currentFile = nil
+		l.fileBuffer = nil
+		l.output = os.Stderr
+		l.stderrFallback = true
+		l.openFailed = true
+		return oldPath, ""
+	}
+	newPath = File.Name()
+	if oldPath != "" {
+		atomic.AddInt64(&l.rotations, 1)
+		if l.CompressOnRotate {
+			l.compressRotated(oldPath)
+		}
+	}
+	previousDate := l.currentDate
+	l.setCurrentFile(File)
+	l.currentDate, l.currentTime = l.fileNamePattern().date(date), date
+	l.currentSize = fileSize(File)
+	// 切换文件后检查并执行清理操作
+	l.notifyCleanup()
+	// 跨天后在后台压缩前一天的日志，前一天的文件已关闭，不会与当前写入冲突
+	if l.Compress && !l.CompressOnRotate && previousDate != "" && previousDate != l.currentDate {
+		l.compressWg.Add(1)
+		go func() {
+			defer l.compressWg.Done()
+			l.compressLogsOfDate(previousDate)
+		}()
+	}
+	return oldPath, newPath
+}
+
+// 当前周期已存在的最大文件序号，已被压缩的序号不再追加，返回下一个序号
+func (l *Log) latestFileIndex(now time.Time) int {
+	if l.BackupNameScheme == BackupNameTimestamp {
+		return 0
+	}
+	pattern := l.fileNamePattern()
+	latest := 0
+	for index := 1; ; index++ {
+		path := filepath.Join(l.FilePath, pattern.fileName(now, index))
+		if _, err := os.Stat(path); err == nil {
+			latest = index
+			continue
+		}
+		if _, err := os.Stat(path + ".gz"); err == nil {
+			latest = index + 1
+			continue
+		}
+		return latest
+	}
+}
+
+// 日志目录，SetPath 会在写入协程中修改，其他协程通过该方法读取
+func (l *Log) logDir() string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.FilePath
+}
+
+// 打开文件失败时按指数退避重试，应对网络文件系统等的短暂故障
+func (l *Log) openLogFileWithRetry(FileName string) (*os.File, error) {
+	backoff := openBackoff
+	var err error
+	for attempt := 0; attempt < openAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		var File *os.File
+		if File, err = l.openLogFile(FileName); err == nil {
+			return File, nil
+		}
+	}
+	return nil, err
+}
+
+// 以追加模式打开日志目录下的文件
+func (l *Log) openLogFile(FileName string) (*os.File, error) {
+	return openFile(l.FilePath+"/"+FileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, l.filePerm())
+}
+
+func (l *Log) bufferSize() int {
+	if l.BufferSize <= 0 {
+		return defaultBufferSize
+	}
+	return l.BufferSize
+}
+
+func (l *Log) dirPerm() os.FileMode {
+	if l.DirPerm == 0 {
+		return defaultDirPerm
+	}
+	return l.DirPerm
+}
+
+func (l *Log) filePerm() os.FileMode {
+	if l.FilePerm == 0 {
+		return defaultFilePerm
+	}
+	return l.FilePerm
+}
+
+func (l *Log) Errorf(format string, a ...interface{}) {
+	l.syncWriteLog(Error, nil, 0, format, a...)
+}
+
+// 写入 Error 级别日志，等待写入磁盘后退出进程
+func (l *Log) Fatalf(format string, a ...interface{}) {
+	l.syncWriteLog(Error, nil, 0, format, a...)
+	l.Flush()
+	exitFunc(1)
+}
+
+func (l *Log) Warnf(format string, a ...interface{}) {
+	l.syncWriteLog(Warn, nil, 0, format, a...)
+}
+
+func (l *Log) Infof(format string, a ...interface{}) {
+	l.syncWriteLog(Info, nil, 0, format, a...)
+}
+
+func (l *Log) Debugf(format string, a ...interface{}) {
+	l.syncWriteLog(Debug, nil, 0, format, a...)
+}
+
+// 设置自定义输出，设置后日志写入 w 而不是按日期生成的文件
+func (l *Log) SetOutput(w io.Writer) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.output = w
+	l.stderrFallback = false
+	l.openFailed = false
+}
+
+func (l *Log) getOutput() io.Writer {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.output
+}
+
+// 设置按天切换文件后是否压缩前一天的日志
+func (l *Log) SetCompress(enable bool) {
+	l.Compress = enable
+}
+
+// 设置每次切换文件（按天或按大小）后是否立即将刚关闭的文件压缩为 .gz，开启后不再按天批量压缩
+func (l *Log) SetCompressOnRotate(enable bool) {
+	l.CompressOnRotate = enable
+}
+
+// 设置输出格式，FormatText 为默认的文本格式，FormatJSON 每行输出一个 JSON 对象
+func (l *Log) SetFormat(format int) {
+	l.Format = format
+}
+
+// 设置额外跳过的调用栈层数，封装了日志方法时用于定位真实调用处
+func (l *Log) SetCallerSkip(skip int) {
+	l.CallerSkip = skip
+}
+
+// 设置调用处是否只输出文件名，例如 logger.go:42，关闭时输出编译时的完整路径
+func (l *Log) SetShortCaller(enable bool) {
+	l.ShortCaller = enable
+}
+
+// 设置函数名是否输出完整限定名，例如 LogCollection/Logger.(*Log).Infof，关闭时去掉包路径和包名
+func (l *Log) SetFullFuncName(enable bool) {
+	l.FullFuncName = enable
+}
+
+// 设置是否跳过调用处的查找，关闭后日志行不包含文件、行号和函数名，可减少热点路径的开销
+func (l *Log) SetDisableCaller(disable bool) {
+	l.DisableCaller = disable
+}
+
+// 设置是否在日志目录中维护 current.log 软链接，每次打开新文件后指向该文件
+func (l *Log) SetSymlinkCurrent(enable bool) {
+	l.SymlinkCurrent = enable
+}
+
+// 设置是否在日志行中输出当前协程的 ID，ID 从运行时栈信息中解析，默认关闭以避免额外开销
+func (l *Log) SetShowGoroutineID(enable bool) {
+	l.ShowGoroutineID = enable
+}
+
+// 设置是否在每行日志前输出进程内递增的序号，例如 seq:12，可用于发现下游丢失或乱序的日志
+func (l *Log) SetShowSequence(enable bool) {
+	l.ShowSequence = enable
+}
+
+// 设置切换文件后的回调，可用于上传旧文件或发送通知，在 SetLogger 之前调用生效。
+// 回调在写入协程中执行，执行期间日志写入会暂停。回调中不能调用 Flush、Rotate、Reopen、SetPath、Close 等
+// 需要等待写入协程的方法，否则会一直等待自身；写日志也只在通道未满时不会阻塞，耗时或需要这些方法的操作应放到新的协程中
+func (l *Log) SetOnRotate(fn func(oldPath, newPath string)) {
+	l.OnRotate = fn
+}
+
+// 设置是否同时将日志输出到标准错误
+func (l *Log) SetConsoleOutput(enable bool) {
+	l.ConsoleOutput = enable
+}
+
+// 设置最多保留的日志文件个数，超出的旧文件在清理时删除
+func (l *Log) SetMaxBackups(MaxBackups int) {
+	l.MaxBackups = MaxBackups
+}
+
+// 设置所有日志文件的总字节数上限，超出时从最旧的文件开始删除
+func (l *Log) SetMaxTotalSize(MaxTotalSize int64) {
+	l.MaxTotalSize = MaxTotalSize
+}
+
+// 设置创建日志目录和日志文件时使用的权限
+func (l *Log) SetPermissions(DirPerm, FilePerm os.FileMode) {
+	l.DirPerm = DirPerm
+	l.FilePerm = FilePerm
+}
+
+// 设置日志行中时间戳的格式，可以是任意 time 布局、UnixTimeFormat 或 MilliTimeFormat/MicroTimeFormat，不影响文件名
+func (l *Log) SetTimeFormat(TimeFormat string) {
+	l.TimeFormat = TimeFormat
+}
+
+// 设置每行日志的结束符，如 "\r\n" 或 "\x00"，为空时恢复默认的 "\n"
+func (l *Log) SetLineTerminator(terminator string) {
+	l.LineTerminator = terminator
+}
+
+// 设置单条消息的最大字节数，超出部分被截断并以 ...[truncated] 标记，0 表示不限制
+func (l *Log) SetMaxMessageBytes(n int) {
+	l.MaxMessageBytes = n
+}
+
+// 设置时间戳和文件名是否使用 UTC 时间
+func (l *Log) SetUseUTC(enable bool) {
+	l.UseUTC = enable
+}
+
+// 设置日志文件名格式，清理时只处理符合该格式的文件
+func (l *Log) SetFileNamePattern(pattern FileNamePattern) {
+	l.FileNamePattern = pattern
+}
+
+// 设置切换文件的周期，RotateMonthly 时每月一个文件，如 2006-01.log。
+// MaxDay 仍按天计算保留时间，以文件最后修改时间为准，按月归档的文件在当月结束 MaxDay 天后才会被清理
+func (l *Log) SetRotateInterval(interval int) {
+	l.RotateInterval = interval
+}
+
+// 设置通道已满时是否丢弃消息，丢弃的条数可通过 DroppedCount 获取
+func (l *Log) SetDropWhenFull(enable bool) {
+	l.DropWhenFull = enable
+}
+
+// 设置通道已满时最多等待的时间，超时后丢弃消息并计入 DroppedCount，0 表示一直阻塞，开启 DropWhenFull 时不生效
+func (l *Log) SetSendTimeout(timeout time.Duration) {
+	l.SendTimeout = timeout
+}
+
+// 设置异步写入通道的容量，在 SetLogger 之前调用生效
+func (l *Log) SetBufferSize(size int) {
+	l.BufferSize = size
+}
+
+// 设置缓冲写入的刷新间隔，大于 0 时开启缓冲，在 SetLogger 之前调用生效
+func (l *Log) SetFlushInterval(interval time.Duration) {
+	l.FlushInterval = interval
+}
+
+// 设置单个日志文件的最大字节数，超过后按序号切分
+func (l *Log) SetMaxSize(MaxSize int64) {
+	l.MaxSize = MaxSize
+}
+
+// 获取日志级别对应的名称，按 LevelStyle 选择写法
+func (l *Log) GetLevelString(level int) string {
+	return levelToken(level, l.LevelStyle)
+}
+
+// 日志级别对应的名称，与 ParseLevel 互为逆操作，无效的级别返回空字符串
+func LevelString(level int) string {
+	var Level string
+	switch level {
+	case Debug:
+		Level = "Debug"
+	case Info:
+		Level = "Info"
+	case Warn:
+		Level = "Warn"
+	case Error:
+		Level = "Error"
+	}
+	return Level
+}
+
+// 设置日志级别，可在运行中随时调整，无效的级别会被忽略
+func (l *Log) SetLevel(level int) {
+	switch level {
+	case Debug, Info, Warn, Error:
+		atomic.StoreInt32(&l.LogLevel, int32(level))
+	}
+}
+
+// 获取当前日志级别
+func (l *Log) Level() int {
+	return int(atomic.LoadInt32(&l.LogLevel))
+}
+
+// 判断该级别的日志是否会被输出，可在构造开销较大的消息前调用，可在任意协程中调用
+func (l *Log) IsLevelEnabled(level int) bool {
+	return level >= l.Level()
+}
+
+func (l *Log) GetConf() {
+	conf := l.GetConfig()
+	fmt.Println(l.GetLevelString(conf.Level), conf.FilePath, conf.MaxDay)
+}
+
+// Config 中的 MaxDay，永久保留时返回 -1，避免 0 被 NewLoggerWithConfig 当作默认天数
+func (l *Log) maxDayConfig() int64 {
+	if l.MaxDay <= 0 {
+		return keepForever
+	}
+	return l.MaxDay
+}
+
+// 获取当前配置，权限、时间格式等未设置的项返回实际生效的默认值
+func (l *Log) GetConfig() Config {
+	return Config{
+		Level:             l.Level(),
+		FilePath:          l.logDir(),
+		MaxDay:            l.maxDayConfig(),
+		MaxSize:           l.MaxSize,
+		MaxBackups:        l.MaxBackups,
+		MaxTotalSize:      l.MaxTotalSize,
+		ConsoleOutput:     l.ConsoleOutput,
+		CallerSkip:        l.CallerSkip,
+		Format:            l.Format,
+		Compress:          l.Compress,
+		DirPerm:           l.dirPerm(),
+		FilePerm:          l.filePerm(),
+		TimeFormat:        l.timeFormat(),
+		UseUTC:            l.UseUTC,
+		FileNamePattern:   l.fileNamePattern(),
+		RotateInterval:    l.RotateInterval,
+		DropWhenFull:      l.DropWhenFull,
+		SendTimeout:       l.SendTimeout,
+		BufferSize:        l.bufferSize(),
+		FlushInterval:     l.FlushInterval,
+		DedupTimeout:      l.DedupTimeout,
+		ShortCaller:       l.ShortCaller,
+		DisableCaller:     l.DisableCaller,
+		SymlinkCurrent:    l.SymlinkCurrent,
+		ShowGoroutineID:   l.ShowGoroutineID,
+		CompressOnRotate:  l.CompressOnRotate,
+		SeparateErrorFile: l.SeparateErrorFile,
+		LineTerminator:    terminatorOrDefault(l.LineTerminator),
+		MaxMessageBytes:   l.MaxMessageBytes,
+		Color:             l.Color,
+		ForceColor:        l.ForceColor,
+		FullFuncName:      l.FullFuncName,
+		ShowSequence:      l.ShowSequence,
+		RingBufferSize:    l.RingBufferSize,
+		SyncOnError:       l.SyncOnError,
+		BackupNameScheme:  l.BackupNameScheme,
+		LevelStyle:        l.LevelStyle,
+	}
+}
+
+// 切换日志文件的周期
+const (
+	RotateDaily = iota
+	RotateHourly
+	RotateMonthly
+)
+
+// 按小时切换时文件名使用的日期布局
+const hourlyLayout = "2006-01-02-15"
+
+// 按月归档时文件名使用的日期布局
+const monthlyLayout = "2006-01"
+
+// 日志文件名格式：Prefix + 按 Layout 格式化的日期 + [.序号] + Suffix
+type FileNamePattern struct {
+	Prefix string // 文件名前缀，如 "app-"
+	Layout string // 日期布局，为空时使用 "2006-01-02"
+	Suffix string // 文件名后缀，为空时使用 ".log"
+}
+
+// 默认的文件名格式，生成 2006-01-02.log
+var defaultFileNamePattern = FileNamePattern{Layout: "2006-01-02", Suffix: ".log"}
+
+// 补全未设置的部分
+func (p FileNamePattern) withDefaults() FileNamePattern {
+	if p.Layout == "" {
+		p.Layout = defaultFileNamePattern.Layout
+	}
+	if p.Suffix == "" {
+		p.Suffix = defaultFileNamePattern.Suffix
+	}
+	return p
+}
+
+// 日志文件对应的日期，用于判断是否需要切换文件
+func (p FileNamePattern) date(data time.Time) string {
+	return data.Format(p.Layout)
+}
+
+// 按大小切分后的文件名，序号为 0 时不带序号
+func (p FileNamePattern) fileName(data time.Time, index int) string {
+	if index == 0 {
+		return p.Prefix + p.date(data) + p.Suffix
+	}
+	return fmt.Sprintf("%s%s.%d%s", p.Prefix, p.date(data), index, p.Suffix)
+}
+
+// 判断文件名是否符合该格式，包含压缩后的 .gz 文件
+func (p FileNamePattern) match(name string) bool {
+	name = strings.TrimSuffix(name, ".gz")
+	return strings.HasPrefix(name, p.Prefix) && strings.HasSuffix(name, p.Suffix)
+}
+
+// 实际使用的文件名格式，未指定布局时按小时切换使用 hourlyLayout，按月归档使用 monthlyLayout
+func (l *Log) fileNamePattern() FileNamePattern {
+	pattern := l.FileNamePattern
+	if pattern.Layout == "" {
+		switch l.RotateInterval {
+		case RotateHourly:
+			pattern.Layout = hourlyLayout
+		case RotateMonthly:
+			pattern.Layout = monthlyLayout
+		}
+	}
+	return pattern.withDefaults()
+}
+
+func formatLogFileName(data time.Time) string {
+	return defaultFileNamePattern.fileName(data, 0)
+}
+
+// 获取文件当前大小，用于追加写入时继续累计
+func fileSize(file *os.File) int64 {
+	info, err := file.Stat()
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// 获取对应文件名，行号，方法名
+func (l *Log) logWithCallerInfo(level int, ctx *logContext, skip int, logline string) logLine {
+	return l.newLogLine(level, ctx, l.lookupCaller(callerDepth+skip), logline)
+}
+
+// 查找调用处信息，depth 为从调用 lookupCaller 的函数到用户调用处的栈深度，开启 DisableCaller 时返回 nil
+func (l *Log) lookupCaller(depth int) *callerInfo {
+	if l.DisableCaller {
+		return nil
+	}
+	pc, file, line, ok := runtime.Caller(depth + 1 + l.CallerSkip)
+	fn := runtime.FuncForPC(pc)
+	// 调用栈层数超出范围时查找失败，使用占位值而不是让日志调用崩溃
+	if !ok || fn == nil {
+		return &callerInfo{file: unknownCaller, line: 0, funcName: unknownCaller}
+	}
+	if l.ShortCaller {
+		file = filepath.Base(file)
+	}
+	return &callerInfo{file: file, line: line, funcName: l.funcName(fn.Name())}
+}
+
+// 调用处查找失败时输出的文件名和函数名
+const unknownCaller = "unknown"
+
+// 日志调用处的信息
+type callerInfo struct {
+	file     string
+	line     int
+	funcName string
+}
+
+// 按输出格式组装一行日志并记下当前时间，caller 为 nil 时省略调用处信息
+func (l *Log) newLogLine(level int, ctx *logContext, caller *callerInfo, logline string) logLine {
+	now := l.now()
+	return logLine{level: level, message: logline, ctx: ctx, text: l.formatEntryAt(now, level, ctx, caller, logline), time: now}
+}
+
+// 按输出格式组装一行时间为 t 的日志
+func (l *Log) formatEntryAt(t time.Time, level int, ctx *logContext, caller *callerInfo, logline string) string {
+	e := Entry{Level: level, Time: t, Message: logline}
+	if ctx != nil {
+		e.Name, e.Tags, e.Fields = ctx.name, ctx.tags, ctx.fields
+	}
+	if caller != nil {
+		e.File, e.Line, e.Func = caller.file, caller.line, caller.funcName
+	}
+	if l.ShowGoroutineID {
+		e.Goroutine = currentGoroutineID()
+	}
+	return string(l.formatter().Format(e))
+}
+
+// 从 runtime.Stack 的第一行 "goroutine 12 [running]:" 中解析当前协程 ID
+func currentGoroutineID() uint64 {
+	var buf [64]byte
+	stack := buf[:runtime.Stack(buf[:], false)]
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	if i := bytes.IndexByte(stack, ' '); i >= 0 {
+		stack = stack[:i]
+	}
+	id, _ := strconv.ParseUint(string(stack), 10, 64)
+	return id
+}
+
+// 当前时间，开启 UseUTC 时转换为 UTC
+func (l *Log) now() time.Time {
+	if l.UseUTC {
+		return l.clockNow().UTC()
+	}
+	return l.clockNow()
+}
+
+// 时钟给出的当前时间，未设置 clock 时使用 time.Now
+func (l *Log) clockNow() time.Time {
+	if l.clock != nil {
+		return l.clock()
+	}
+	return time.Now()
+}
+
+// 替换时钟，仅供测试使用，需在 SetLogger 之前调用
+func (l *Log) setClock(clock func() time.Time) {
+	l.clock = clock
+}
+
+func (l *Log) timeFormat() string {
+	if l.TimeFormat == "" {
+		return defaultTimeFormat
+	}
+	return l.TimeFormat
+}
+
+// 按 FullFuncName 选择输出完整限定名或只输出方法名
+func (l *Log) funcName(fullName string) string {
+	if l.FullFuncName {
+		return fullName
+	}
+	return getFunctionName(fullName)
+}
+
+// 获取对应的方法名，去掉包路径和包名，保留接收者和外层函数，
+// 例如 pkg.(*Type).Method 得到 (*Type).Method，pkg.Outer.func1 得到 Outer.func1。
+// 运行时会把包路径最后一段中的 "." 转义为 %2e，未转义时 gopkg.in/yaml.v3 这类以 .vN 结尾的包路径也能正确去掉
+func getFunctionName(fullName string) string {
+	name := fullName
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	// 包名之后的 vN 仍属于包路径，只有其后还有内容时才跳过，避免误删名为 vN 的函数
+	if i := strings.Index(name, "."); i > 0 && isVersionSuffix(name[:i]) {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// 判断是否为 v2、v3 这样的主版本号
+func isVersionSuffix(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// 清除过期日志
+func (l *Log) clearOldLogs() error {
+	// 需要清除的日期范围，MaxDay 不为正数时不按时间清理，避免删除正在写入的文件
+	var cutoffDate time.Time
+	if l.MaxDay > 0 {
+		cutoffDate = l.clockNow().AddDate(0, 0, -int(l.MaxDay))
+	}
+
+	dir := l.logDir()
+	l.index.mutex.Lock()
+	defer l.index.mutex.Unlock()
+	indexed, err := l.indexedLogFiles(dir)
+	if err != nil {
+		return fmt.Errorf("failed to clear old logs:%v", err)
+	}
+	var logFiles []logFileInfo
+	for _, file := range indexed {
+		if l.BackupNameScheme == BackupNameTimestamp {
+			// 正在写入的文件固定不删除，旧文件按文件名中的切分时间计算保留期
+			if filepath.Base(file.path) == l.activeFileName(time.Time{}, 0) {
+				continue
+			}
+			if t, ok := l.backupTime(filepath.Base(file.path)); ok {
+				file.modTime = t
+			}
+		}
+		// 检查文件日期是否早于需要清除的日期范围
+		if file.modTime.Before(cutoffDate) {
+			if err = l.removeLogFile(file.path); err != nil {
+				return fmt.Errorf("failed to clear old logs:%v", err)
+			}
+			continue
+		}
+		logFiles = append(logFiles, file)
+	}
+
+	// 按修改时间从新到旧排序，超出 MaxBackups 的旧文件全部删除，正在写入的文件计入个数但不删除
+	sort.Slice(logFiles, func(i, j int) bool {
+		return logFiles[i].modTime.After(logFiles[j].modTime)
+	})
+	if l.MaxBackups > 0 && len(logFiles) > l.MaxBackups {
+		kept := logFiles[:0]
+		for _, file := range logFiles {
+			if len(kept) < l.MaxBackups || l.index.live[file.path] {
+				kept = append(kept, file)
+				continue
+			}
+			if err = l.removeLogFile(file.path); err != nil {
+				return fmt.Errorf("failed to clear old logs:%v", err)
+			}
+		}
+		logFiles = kept
+	}
+
+	// 总大小超出 MaxTotalSize 时从最旧的文件开始删除，正在写入的文件计入总大小但不删除
+	if l.MaxTotalSize > 0 {
+		var totalSize int64
+		for _, file := range logFiles {
+			totalSize += file.size
+		}
+		for i := len(logFiles) - 1; i >= 0 && totalSize > l.MaxTotalSize; i-- {
+			if l.index.live[logFiles[i].path] {
+				continue
+			}
+			if err = l.removeLogFile(logFiles[i].path); err != nil {
+				return fmt.Errorf("failed to clear old logs:%v", err)
+			}
+			totalSize -= logFiles[i].size
+		}
+	}
+	return nil
+}
+
+// 删除日志文件并从索引中移除，文件已被外部删除时不报错，调用方需持有 l.index.mutex
+func (l *Log) removeLogFile(path string) error {
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	delete(l.index.files, path)
+	delete(l.index.live, path)
+	if err == nil {
+		log.Printf("Removed log file: %s\n", path)
+	}
+	return nil
+}
+
+// 启动清理协程，立即执行一次清理，之后按 cleanupInterval 定时执行
+func (l *Log) startCleanup() {
+	l.cleanupNotify = make(chan struct{}, 1)
+	l.cleanupStop = make(chan struct{})
+	l.cleanupWg.Add(1)
+	go l.cleanupLoop()
+	l.notifyCleanup()
+}
+
+func (l *Log) cleanupLoop() {
+	defer l.cleanupWg.Done()
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.runCleanup()
+		case <-l.cleanupNotify:
+			l.runCleanup()
+		case <-l.cleanupStop:
+			return
+		}
+	}
+}
+
+// 通知清理协程执行清理，已有待执行的通知时直接返回
+func (l *Log) notifyCleanup() {
+	if l.cleanupNotify == nil {
+		return
+	}
+	select {
+	case l.cleanupNotify <- struct{}{}:
+	default:
+	}
+}
+
+func (l *Log) runCleanup() {
+	if err := l.clearOldLogs(); err != nil {
+		l.internalError("clean old logs", err)
+	}
+	atomic.AddInt64(&l.cleanups, 1)
+}
+
+// 停止清理协程
+func (l *Log) stopCleanup() {
+	if l.cleanupStop == nil {
+		return
+	}
+	close(l.cleanupStop)
+	l.cleanupWg.Wait()
+	l.cleanupStop = nil
+}
+
+// 关闭当前文件和错误日志文件
+func (l *Log) closeFiles() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_ = l.closeErrorFile()
 	if l.currentFile != nil {
+		l.flushFileBuffer()
 		_ = l.currentFile.Close()
+		l.currentFile = nil
+		l.fileBuffer = nil
+	}
+}
+
+// 清理时收集的日志文件信息
+type logFileInfo struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// 将相对路径转换为绝对路径
+func relativePathToAbsPath(Path string) (string, error) {
+	absolutePath, err := filepath.Abs(Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %v", err)
+	}
+	return absolutePath, nil
+}
+
+// 关闭对应的写入通道，等待缓冲中的日志全部写入后再关闭文件，重复调用是安全的空操作并返回 nil，关闭后写入的日志被丢弃
+func (l *Log) Close() error {
+	l.closeMutex.Lock()
+	defer l.closeMutex.Unlock()
+	l.stopWriter()
+	return l.closeResources()
+}
+
+// 关闭日志，最多等待 d，超时返回错误。关闭流程整体在后台协程中进行，写入文件或远程发送卡住时不会阻塞调用方，
+// 卡住的操作返回后再继续写完剩余日志并释放文件等资源，之后的 Close 会等待这次关闭结束
+func (l *Log) CloseWithTimeout(d time.Duration) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Close()
+	}()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("close timed out after %v", d)
+	}
+}
+
+// 等待后台任务结束并关闭 syslog、HTTP 发送端和当前文件，返回刷新缓冲区或关闭文件时的错误
+func (l *Log) closeResources() error {
+	l.compressWg.Wait()
+	l.stopCleanup()
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.syslogWriter != nil {
+		_ = l.syslogWriter.close()
+		l.syslogWriter = nil
+	}
+	if l.httpSink != nil {
+		l.httpSink.close()
+		l.httpSink = nil
+	}
+	errorFileErr := l.closeErrorFile()
+	if l.currentFile == nil {
+		return errorFileErr
+	}
+	err := l.flushFileBuffer()
+	if closeErr := l.currentFile.Close(); err == nil {
+		err = closeErr
+	}
+	l.currentFile = nil
+	l.fileBuffer = nil
+	if err == nil {
+		err = errorFileErr
 	}
+	return err
 }
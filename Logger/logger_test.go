@@ -1,7 +1,21 @@
 package Logger
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unicode/utf8"
 )
 
 func TestLog_SetLogger(t *testing.T) {
@@ -10,3 +24,2118 @@ func TestLog_SetLogger(t *testing.T) {
 	LogClient.GetConf()
 	LogClient.Infof("test error : %s", "test")
 }
+
+// 等待异步写入完成后读取当天的日志文件
+func readTodayLog(t *testing.T, LogClient Logger, dir string) string {
+	t.Helper()
+	LogClient.Flush()
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestLog_SetLoggerTwice(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	defer LogClient.Close()
+	LogClient.SetLogger(Info, dir, 6)
+	LogClient.Infof("first setup")
+	LogClient.Flush()
+	goroutines := runtime.NumGoroutine()
+	fds, fdErr := os.ReadDir("/proc/self/fd")
+
+	for i := 0; i < 5; i++ {
+		LogClient.SetLogger(Info, dir, 6)
+	}
+	LogClient.Infof("after repeated setup")
+	LogClient.Flush()
+
+	if n := runtime.NumGoroutine(); n > goroutines {
+		t.Errorf("goroutines = %d after repeated SetLogger, want %d", n, goroutines)
+	}
+	if fdErr == nil {
+		if after, err := os.ReadDir("/proc/self/fd"); err == nil && len(after) > len(fds) {
+			t.Errorf("open descriptors = %d after repeated SetLogger, want %d", len(after), len(fds))
+		}
+	}
+	content := readTodayLog(t, LogClient, dir)
+	if strings.Count(content, "\n") != 2 || !strings.Contains(content, "after repeated setup") {
+		t.Errorf("unexpected content %q", content)
+	}
+}
+
+func TestLog_AppendAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	for _, msg := range []string{"before restart", "after restart"} {
+		LogClient := NewLogger()
+		LogClient.SetLogger(Info, dir, 6)
+		LogClient.Infof(msg)
+		if err := LogClient.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected a single log file, got %d", len(entries))
+	}
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "before restart") || !strings.Contains(string(data), "after restart") {
+		t.Errorf("expected both entries in one file, got %q", data)
+	}
+}
+
+func TestLog_AppendAfterRestartResumesIndex(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetMaxSize(300)
+	LogClient.SetLogger(Info, dir, 6)
+	for i := 0; i < 4; i++ {
+		LogClient.Infof("first run %d %s", i, strings.Repeat("x", 100))
+	}
+	LogClient.Close()
+	index := LogClient.(*Log).fileIndex
+	if index == 0 {
+		t.Fatal("expected the first run to rotate by size")
+	}
+
+	LogClient = NewLogger()
+	LogClient.SetMaxSize(300)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	if got := LogClient.(*Log).fileIndex; got != index {
+		t.Errorf("fileIndex after restart = %d, want %d", got, index)
+	}
+}
+
+func TestLog_LevelFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "DeBuG")
+	LogClient := NewLogger()
+	LogClient.SetLogger(0, t.TempDir(), 6)
+	if level := LogClient.Level(); level != Debug {
+		t.Errorf("level = %d, want Debug from LOG_LEVEL", level)
+	}
+	LogClient.Close()
+
+	LogClient = NewLogger()
+	LogClient.SetLogger(Error, t.TempDir(), 6)
+	defer LogClient.Close()
+	if level := LogClient.Level(); level != Error {
+		t.Errorf("level = %d, want explicit Error to win over LOG_LEVEL", level)
+	}
+
+	t.Setenv("LOG_LEVEL", "verbose")
+	if level := defaultLevel(); level != Info {
+		t.Errorf("default level = %d, want Info for invalid LOG_LEVEL", level)
+	}
+}
+
+func TestLog_LevelFilter(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Error, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("info message")
+	LogClient.Errorf("error message")
+
+	content := readTodayLog(t, LogClient, dir)
+	if strings.Contains(content, "info message") {
+		t.Errorf("info message should be filtered at Error level: %q", content)
+	}
+	if !strings.Contains(content, "[Error]") || !strings.Contains(content, "error message") {
+		t.Errorf("error message missing: %q", content)
+	}
+}
+
+func TestLog_Debugf(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Debug, dir, 6)
+	defer LogClient.Close()
+	LogClient.Debugf("debug message: %d", 1)
+
+	content := readTodayLog(t, LogClient, dir)
+	if !strings.Contains(content, "[Debug]") || !strings.Contains(content, "debug message: 1") {
+		t.Errorf("debug message missing: %q", content)
+	}
+}
+
+func TestLog_LevelOrdering(t *testing.T) {
+	levels := []int{Debug, Info, Warn, Error}
+	for _, level := range levels {
+		dir := t.TempDir()
+		LogClient := NewLogger()
+		LogClient.SetLogger(level, dir, 6)
+		LogClient.Debugf("debug message")
+		LogClient.Infof("info message")
+		LogClient.Warnf("warn message")
+		LogClient.Errorf("error message")
+
+		content := readTodayLog(t, LogClient, dir)
+		LogClient.Close()
+		for _, msgLevel := range levels {
+			token := "[" + LogClient.(*Log).GetLevelString(msgLevel) + "]"
+			if got, want := strings.Contains(content, token), msgLevel >= level; got != want {
+				t.Errorf("level %d: contains %s = %v, want %v", level, token, got, want)
+			}
+		}
+	}
+}
+
+func TestLog_ErrorfKeepsLogLevel(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Errorf("error message")
+	readTodayLog(t, LogClient, dir)
+	LogClient.GetConf()
+
+	if level := LogClient.Level(); level != Info {
+		t.Errorf("LogLevel changed to %d after Errorf, want %d", level, Info)
+	}
+}
+
+func TestLog_MaxSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetMaxSize(300)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	for i := 0; i < 6; i++ {
+		LogClient.Infof("size rotation message %d %s", i, strings.Repeat("x", 100))
+	}
+	readTodayLog(t, LogClient, dir)
+
+	for index := 1; index <= 2; index++ {
+		name := defaultFileNamePattern.fileName(time.Now(), index)
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected rotated file %s: %v", name, err)
+		}
+	}
+}
+
+func TestLog_SymlinkCurrent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require extra privileges on windows")
+	}
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetMaxSize(300)
+	LogClient.SetSymlinkCurrent(true)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	for i := 0; i < 6; i++ {
+		LogClient.Infof("symlink message %d %s", i, strings.Repeat("x", 100))
+	}
+	LogClient.Flush()
+
+	target, err := os.Readlink(filepath.Join(dir, currentSymlinkName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target == defaultFileNamePattern.fileName(time.Now(), 0) {
+		t.Errorf("expected symlink to follow rollover, still points to %s", target)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, currentSymlinkName))
+	if err != nil {
+		t.Fatalf("symlink does not resolve: %v", err)
+	}
+	if !strings.Contains(string(data), "symlink message 5") {
+		t.Errorf("expected symlink to resolve to the active file, got %q", data)
+	}
+}
+
+func TestLog_SymlinkSurvivesCleanup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require extra privileges on windows")
+	}
+	dir := t.TempDir()
+	paths := createDummyLogs(t, dir, 4, 1)
+	LogClient := NewLogger()
+	LogClient.SetMaxBackups(2)
+	LogClient.SetSymlinkCurrent(true)
+	LogClient.SetLogger(Info, dir, 30)
+	defer LogClient.Close()
+	LogClient.Infof("symlink cleanup")
+	LogClient.Flush()
+	if err := LogClient.(*Log).clearOldLogs(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(dir, currentSymlinkName)); err != nil {
+		t.Errorf("expected %s to survive cleanup: %v", currentSymlinkName, err)
+	}
+	for i, path := range paths {
+		_, err := os.Stat(path)
+		if exists := err == nil; exists != (i < 2) {
+			t.Errorf("%s exists = %v, want %v", filepath.Base(path), exists, i < 2)
+		}
+	}
+}
+
+func TestLog_OnRotate(t *testing.T) {
+	dir := t.TempDir()
+	yesterday := time.Now().AddDate(0, 0, -1)
+	var oldPath, newPath string
+	LogClient := NewLogger()
+	LogClient.SetOnRotate(func(o, n string) {
+		oldPath, newPath = o, n
+		LogClient.Infof("rotated")
+	})
+	LogClient.SetLogger(Info, dir, 6)
+	// 模拟当前文件是前一天的文件，下一次写入时触发跨天切换
+	l := LogClient.(*Log)
+	LogClient.Flush()
+	l.mutex.Lock()
+	_ = l.currentFile.Close()
+	File, err := l.openLogFile(formatLogFileName(yesterday))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.setCurrentFile(File)
+	l.currentDate = yesterday.Format("2006-01-02")
+	l.mutex.Unlock()
+	LogClient.Infof("today line")
+	LogClient.Flush()
+	LogClient.Close()
+
+	if want := filepath.Join(dir, formatLogFileName(yesterday)); oldPath != want {
+		t.Errorf("oldPath = %q, want %q", oldPath, want)
+	}
+	if want := filepath.Join(dir, formatLogFileName(time.Now())); newPath != want {
+		t.Errorf("newPath = %q, want %q", newPath, want)
+	}
+}
+
+func TestLog_ClockDayRollover(t *testing.T) {
+	dir := t.TempDir()
+	var mutex sync.Mutex
+	current := time.Date(2024, 3, 1, 23, 59, 59, 0, time.Local)
+	LogClient := NewLogger()
+	LogClient.(*Log).setClock(func() time.Time {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return current
+	})
+	LogClient.SetLogger(Info, dir, 0)
+	defer LogClient.Close()
+	LogClient.Infof("before midnight")
+	LogClient.Flush()
+	mutex.Lock()
+	current = current.Add(2 * time.Second)
+	mutex.Unlock()
+	LogClient.Infof("after midnight")
+	LogClient.Flush()
+
+	for name, want := range map[string]string{
+		"2024-03-01.log": "before midnight",
+		"2024-03-02.log": "after midnight",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), want) || strings.Count(string(data), "\n") != 1 {
+			t.Errorf("%s = %q, want only %q", name, data, want)
+		}
+	}
+}
+
+func TestLog_RolloverUsesEntryTime(t *testing.T) {
+	dir := t.TempDir()
+	var mutex sync.Mutex
+	current := time.Date(2024, 3, 1, 23, 59, 59, 0, time.Local)
+	LogClient := NewLogger()
+	l := LogClient.(*Log)
+	l.setClock(func() time.Time {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return current
+	})
+	LogClient.SetLogger(Info, dir, 0)
+	defer LogClient.Close()
+	// 阻塞写入协程，使午夜前的日志在跨过午夜后才被写入
+	release := make(chan struct{})
+	l.addPending()
+	l.logChannels <- logLine{action: func() { <-release }}
+	LogClient.Infof("queued before midnight")
+	mutex.Lock()
+	current = current.Add(2 * time.Second)
+	mutex.Unlock()
+	close(release)
+	LogClient.Infof("after midnight")
+	LogClient.Flush()
+
+	for name, want := range map[string]string{
+		"2024-03-01.log": "queued before midnight",
+		"2024-03-02.log": "after midnight",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), want) || strings.Count(string(data), "\n") != 1 {
+			t.Errorf("%s = %q, want only %q", name, data, want)
+		}
+	}
+}
+
+func TestLog_CloseTwice(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	LogClient.Infof("before close")
+	if err := LogClient.Close(); err != nil {
+		t.Fatalf("first Close: %v", err)
+	}
+	if err := LogClient.Close(); err != nil {
+		t.Errorf("second Close = %v, want nil", err)
+	}
+	if err := LogClient.CloseWithTimeout(time.Second); err != nil {
+		t.Errorf("CloseWithTimeout after Close = %v, want nil", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(data), "before close") != 1 {
+		t.Errorf("log = %q, want the line written once", data)
+	}
+
+	// 延迟关闭和显式关闭在不同协程中同时执行
+	LogClient = NewLogger()
+	LogClient.SetLogger(Info, t.TempDir(), 6)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := LogClient.Close(); err != nil {
+				t.Errorf("concurrent Close: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLog_LogAfterClose(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	LogClient.Infof("before close")
+	LogClient.Close()
+	LogClient.Infof("after close")
+	LogClient.Named("db").Errorf("after close")
+	LogClient.InfoRaw("after close")
+	LogClient.Flush()
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "before close") || strings.Contains(string(data), "after close") {
+		t.Errorf("log = %q, want only the line before close", data)
+	}
+
+	// 关闭与其他协程的写入同时进行
+	LogClient = NewLogger()
+	LogClient.SetLogger(Info, t.TempDir(), 6)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				LogClient.Infof("racing close %d", j)
+			}
+		}()
+	}
+	LogClient.Close()
+	wg.Wait()
+}
+
+// 并发安全的 bytes.Buffer，供写入协程和测试同时访问
+type syncBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
+}
+
+func TestLog_SetOutput(t *testing.T) {
+	dir := t.TempDir()
+	buf := &syncBuffer{}
+	LogClient := NewLogger()
+	LogClient.SetOutput(buf)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("buffer message: %s", "ok")
+	LogClient.Flush()
+
+	content := buf.String()
+	if !strings.HasPrefix(content, "[Info][") || !strings.Contains(content, "funcName:TestLog_SetOutput;message:buffer message: ok\n") {
+		t.Errorf("unexpected output: %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, formatLogFileName(time.Now()))); !os.IsNotExist(err) {
+		t.Errorf("log file should not be created when output is set: %v", err)
+	}
+}
+
+func TestLog_SetLoggerNoStdout(t *testing.T) {
+	dir := t.TempDir()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	LogClient := NewLogger()
+	err = LogClient.SetLogger(Info, dir, 6)
+	LogClient.Infof("quiet message")
+	LogClient.Close()
+	os.Stdout = stdout
+	_ = writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output) != 0 {
+		t.Errorf("expected no stdout output from SetLogger, got %q", output)
+	}
+}
+
+func TestLog_ConsoleOutput(t *testing.T) {
+	dir := t.TempDir()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = writer
+	defer func() { os.Stderr = stderr }()
+
+	LogClient := NewLogger()
+	LogClient.SetConsoleOutput(true)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Debugf("console debug message")
+	LogClient.Infof("console info message")
+
+	content := readTodayLog(t, LogClient, dir)
+	os.Stderr = stderr
+	_ = writer.Close()
+	console, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, got := range map[string]string{"file": content, "console": string(console)} {
+		if !strings.Contains(got, "console info message") {
+			t.Errorf("%s missing info message: %q", name, got)
+		}
+		if strings.Contains(got, "console debug message") {
+			t.Errorf("%s should not contain filtered debug message: %q", name, got)
+		}
+	}
+}
+
+func TestLog_CloseDrainsPending(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	const total = 5000
+	for i := 0; i < total; i++ {
+		LogClient.Infof("pending message %d", i)
+	}
+	LogClient.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != total {
+		t.Errorf("got %d lines after Close, want %d", lines, total)
+	}
+}
+
+func TestLog_Flush(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("flushed message")
+	LogClient.Flush()
+
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "flushed message") {
+		t.Errorf("message missing after Flush: %q", data)
+	}
+}
+
+func TestLog_CallerSkip(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetCallerSkip(1)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	logf := func(format string, a ...interface{}) {
+		LogClient.Infof(format, a...)
+	}
+	_, file, line, _ := runtime.Caller(0)
+	logf("wrapped message")
+
+	content := readTodayLog(t, LogClient, dir)
+	if want := fmt.Sprintf("fileLine:%s:%d ", file, line+1); !strings.Contains(content, want) {
+		t.Errorf("expected caller %q in %q", want, content)
+	}
+}
+
+func TestLog_CallerLookupFailure(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetCallerSkip(1000)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("deep caller message")
+
+	content := readTodayLog(t, LogClient, dir)
+	if !strings.Contains(content, "fileLine:unknown:0 funcName:unknown;message:deep caller message") {
+		t.Errorf("expected unknown caller fallback, got %q", content)
+	}
+}
+
+func TestLog_ShortCaller(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetShortCaller(true)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	_, _, line, _ := runtime.Caller(0)
+	LogClient.Infof("short caller message")
+
+	content := readTodayLog(t, LogClient, dir)
+	want := fmt.Sprintf("fileLine:logger_test.go:%d ", line+1)
+	if !strings.Contains(content, want) {
+		t.Errorf("expected caller %q in %q", want, content)
+	}
+	start := strings.Index(content, "fileLine:")
+	caller := content[start : start+strings.Index(content[start:], " ")]
+	if strings.ContainsAny(caller, `/\`) {
+		t.Errorf("short caller %q contains directory separators", caller)
+	}
+}
+
+// 带接收者的方法，用于验证完整函数名
+type funcNameProbe struct{}
+
+func (funcNameProbe) log(LogClient Logger) {
+	LogClient.Infof("full func name message")
+}
+
+func TestLog_FullFuncName(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetFullFuncName(true)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	funcNameProbe{}.log(LogClient)
+
+	content := readTodayLog(t, LogClient, dir)
+	if !strings.Contains(content, "funcName:LogCollection/Logger.funcNameProbe.log;message:full func name message") {
+		t.Errorf("expected fully qualified function name, got %q", content)
+	}
+}
+
+func TestGetFunctionName(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		want     string
+	}{
+		{"free function", "LogCollection/Logger.TestGetFunctionName", "TestGetFunctionName"},
+		{"main package", "main.main", "main"},
+		{"value receiver", "LogCollection/Logger.funcNameProbe.log", "funcNameProbe.log"},
+		{"pointer receiver", "LogCollection/Logger.(*Log).Infof", "(*Log).Infof"},
+		{"closure", "LogCollection/Logger.TestGetFunctionName.func1", "TestGetFunctionName.func1"},
+		{"closure in method", "github.com/a/b.(*Server).Serve.func2.1", "(*Server).Serve.func2.1"},
+		{"dotted package path", "gopkg.in/yaml.v3.(*Decoder).Decode", "(*Decoder).Decode"},
+		{"dotted package function", "gopkg.in/yaml.v3.Marshal", "Marshal"},
+		{"escaped package path", "gopkg.in/yaml%2ev3.(*Decoder).Decode", "(*Decoder).Decode"},
+		{"function named like a version", "example.com/pkg.v2", "v2"},
+		{"no package", "init", "init"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getFunctionName(tt.fullName); got != tt.want {
+				t.Errorf("getFunctionName(%q) = %q, want %q", tt.fullName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLog_DisableCaller(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetDisableCaller(true)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("no caller message")
+
+	content := readTodayLog(t, LogClient, dir)
+	if !strings.Contains(content, "message:no caller message") {
+		t.Errorf("expected message in %q", content)
+	}
+	if strings.Contains(content, "fileLine:") || strings.Contains(content, "funcName:") {
+		t.Errorf("expected caller fields to be omitted, got %q", content)
+	}
+}
+
+func TestLog_ShowSequence(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetShowSequence(true)
+	LogClient.SetMaxSize(300)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	for i := 0; i < 10; i++ {
+		LogClient.Infof("sequenced message %d", i)
+	}
+	LogClient.Flush()
+
+	// 序号在切换文件后继续递增，按文件序号依次读取
+	var lines []string
+	for index := 0; ; index++ {
+		data, err := os.ReadFile(filepath.Join(dir, defaultFileNamePattern.fileName(time.Now(), index)))
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")...)
+	}
+	if len(lines) != 10 {
+		t.Fatalf("expected 10 lines across rotated files, got %d", len(lines))
+	}
+	last := 0
+	for _, line := range lines {
+		var seq int
+		if _, err := fmt.Sscanf(line, "seq:%d [Info]", &seq); err != nil {
+			t.Fatalf("expected a sequence prefix in %q: %v", line, err)
+		}
+		if seq <= last {
+			t.Errorf("sequence %d is not greater than %d", seq, last)
+		}
+		last = seq
+	}
+}
+
+func TestLog_ShowGoroutineID(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetShowGoroutineID(true)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			LogClient.Infof("goroutine message %d", i)
+		}(i)
+	}
+	wg.Wait()
+
+	content := readTodayLog(t, LogClient, dir)
+	ids := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		start := strings.Index(line, " goroutine:")
+		if start < 0 {
+			t.Fatalf("goroutine id missing in %q", line)
+		}
+		id := line[start+len(" goroutine:"):]
+		id = id[:strings.Index(id, " ")]
+		if id == "" || id == "0" {
+			t.Errorf("invalid goroutine id in %q", line)
+		}
+		ids[id] = true
+	}
+	if len(ids) != 2 {
+		t.Errorf("expected 2 distinct goroutine ids, got %v", ids)
+	}
+}
+
+func TestLog_FormatJSON(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetFormat(FormatJSON)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Warnf("json message %d", 42)
+
+	content := readTodayLog(t, LogClient, dir)
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &entry); err != nil {
+		t.Fatalf("line is not valid JSON: %v: %q", err, content)
+	}
+	for _, key := range []string{"level", "time", "file", "line", "func", "message"} {
+		if _, ok := entry[key]; !ok {
+			t.Errorf("missing key %q in %v", key, entry)
+		}
+	}
+	if entry["level"] != "Warn" || entry["message"] != "json message 42" || entry["func"] != "TestLog_FormatJSON" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
+
+// 在 dir 下创建 count 个按日期命名的日志文件，第 i 个文件的修改时间为 i 小时前
+func createDummyLogs(t *testing.T, dir string, count int, size int) []string {
+	t.Helper()
+	paths := make([]string, count)
+	for i := 0; i < count; i++ {
+		modTime := time.Now().Add(-time.Duration(i) * time.Hour)
+		paths[i] = filepath.Join(dir, formatLogFileName(time.Now().AddDate(0, 0, -i)))
+		if err := os.WriteFile(paths[i], []byte(strings.Repeat("x", size)), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(paths[i], modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func TestLog_MaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	paths := createDummyLogs(t, dir, 10, 1)
+	l := &Log{FilePath: dir, MaxDay: 30, MaxBackups: 3}
+	if err := l.clearOldLogs(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, path := range paths {
+		_, err := os.Stat(path)
+		if exists := err == nil; exists != (i < 3) {
+			t.Errorf("%s exists = %v, want %v", filepath.Base(path), exists, i < 3)
+		}
+	}
+}
+
+func TestLog_MaxDayZeroKeepsLogs(t *testing.T) {
+	dir := t.TempDir()
+	paths := createDummyLogs(t, dir, 3, 1)
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 0)
+	defer LogClient.Close()
+	LogClient.Infof("keep forever message")
+	LogClient.Flush()
+	for atomic.LoadInt64(&LogClient.(*Log).cleanups) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if content := readTodayLog(t, LogClient, dir); !strings.Contains(content, "keep forever message") {
+		t.Errorf("expected current file to survive cleanup, got %q", content)
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s to be kept: %v", filepath.Base(path), err)
+		}
+	}
+}
+
+func TestLog_MaxTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	paths := createDummyLogs(t, dir, 5, 100)
+	l := &Log{FilePath: dir, MaxDay: 30, MaxTotalSize: 250}
+	if err := l.clearOldLogs(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, path := range paths {
+		_, err := os.Stat(path)
+		if exists := err == nil; exists != (i < 2) {
+			t.Errorf("%s exists = %v, want %v", filepath.Base(path), exists, i < 2)
+		}
+	}
+}
+
+func TestLog_CleanupKeepsActiveFiles(t *testing.T) {
+	dir := t.TempDir()
+	paths := createDummyLogs(t, dir, 3, 100)
+	LogClient := NewLogger()
+	LogClient.SetMaxTotalSize(100)
+	LogClient.SetMaxBackups(1)
+	LogClient.SetSeparateErrorFile(true)
+	LogClient.SetLogger(Info, dir, 30)
+	defer LogClient.Close()
+	for i := 0; i < 10; i++ {
+		LogClient.Errorf("oversized %s", strings.Repeat("x", 50))
+	}
+	LogClient.Flush()
+	l := LogClient.(*Log)
+	if err := l.clearOldLogs(); err != nil {
+		t.Fatal(err)
+	}
+
+	l.mutex.Lock()
+	active := []string{l.currentFile.Name(), l.errorFile.Name()}
+	l.mutex.Unlock()
+	for _, path := range active {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected active file %s to be kept: %v", filepath.Base(path), err)
+		}
+	}
+	if content := readTodayLog(t, LogClient, dir); strings.Count(content, "oversized") != 10 {
+		t.Errorf("expected active file to keep all lines, got %q", content)
+	}
+	for _, path := range paths[1:] {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected old file %s to be removed, got %v", filepath.Base(path), err)
+		}
+	}
+}
+
+func TestLog_CleanupBounded(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	for i := 0; i < 2000; i++ {
+		LogClient.Infof("cleanup message %d", i)
+	}
+	LogClient.Close()
+
+	// 启动时一次，加上首次写入时的文件切换最多一次
+	if cleanups := atomic.LoadInt64(&LogClient.(*Log).cleanups); cleanups > 2 {
+		t.Errorf("cleanup ran %d times for 2000 lines, want at most 2", cleanups)
+	}
+}
+
+func TestLog_NoSpuriousRotation(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	file := LogClient.(*Log).currentFile
+	LogClient.Infof("first message")
+	readTodayLog(t, LogClient, dir)
+
+	l := LogClient.(*Log)
+	if l.currentFile != file {
+		t.Errorf("first write reopened the log file")
+	}
+	if _, err := time.Parse("2006-01-02", l.currentDate); err != nil {
+		t.Errorf("currentDate %q is not in 2006-01-02 form: %v", l.currentDate, err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d files, want 1", len(entries))
+	}
+}
+
+func TestLog_Fatalf(t *testing.T) {
+	dir := t.TempDir()
+	exitCode := -1
+	exitFunc = func(code int) { exitCode = code }
+	defer func() { exitFunc = os.Exit }()
+
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Fatalf("fatal message")
+
+	// Fatalf 返回前已经写入文件，无需再等待
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "[Error]") || !strings.Contains(string(data), "fatal message") {
+		t.Errorf("fatal message missing: %q", data)
+	}
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+}
+
+func TestLog_SetLoggerError(t *testing.T) {
+	dir := t.TempDir()
+	// 普通文件下无法创建子目录
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	LogClient := NewLogger()
+	if err := LogClient.SetLogger(Info, filepath.Join(file, "logs"), 6); err == nil {
+		t.Error("expected an error for an unusable path")
+	}
+}
+
+func TestLog_Permissions(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	LogClient := NewLogger()
+	LogClient.SetPermissions(0750, 0640)
+	if err := LogClient.SetLogger(Info, dir, 6); err != nil {
+		t.Fatal(err)
+	}
+	defer LogClient.Close()
+
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := dirInfo.Mode().Perm(); perm != 0750 {
+		t.Errorf("dir perm = %o, want 750", perm)
+	}
+	fileInfo, err := os.Stat(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fileInfo.Mode().Perm(); perm != 0640 {
+		t.Errorf("file perm = %o, want 640", perm)
+	}
+}
+
+func TestLog_TimeFormat(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetTimeFormat(time.RFC3339)
+	LogClient.SetFormat(FormatJSON)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("time message")
+
+	content := readTodayLog(t, LogClient, dir)
+	var entry struct {
+		Time string `json:"time"`
+	}
+	if err := json.Unmarshal([]byte(content), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := time.Parse(time.RFC3339, entry.Time); err != nil {
+		t.Errorf("timestamp %q is not RFC3339: %v", entry.Time, err)
+	}
+}
+
+func TestLog_TimePrecision(t *testing.T) {
+	for _, format := range []string{MilliTimeFormat, MicroTimeFormat} {
+		dir := t.TempDir()
+		LogClient := NewLogger()
+		LogClient.SetTimeFormat(format)
+		LogClient.SetLogger(Info, dir, 6)
+		LogClient.Infof("precision message")
+
+		content := readTodayLog(t, LogClient, dir)
+		LogClient.Close()
+		start := strings.Index(content, "][") + 2
+		stamp := content[start : start+len(format)]
+		if _, err := time.Parse(format, stamp); err != nil {
+			t.Errorf("timestamp %q does not match %q: %v", stamp, format, err)
+		}
+		digits := len(format) - strings.LastIndex(format, ".") - 1
+		if fraction := stamp[len(stamp)-digits:]; strings.Trim(fraction, "0123456789") != "" {
+			t.Errorf("expected %d sub-second digits, got %q", digits, fraction)
+		}
+	}
+}
+
+func TestLog_LineTerminator(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLineTerminator("\r\n")
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("first crlf line")
+	LogClient.Infof("second crlf line")
+
+	content := readTodayLog(t, LogClient, dir)
+	if !strings.HasSuffix(content, "message:second crlf line\r\n") || strings.Count(content, "\r\n") != 2 {
+		t.Errorf("expected CRLF terminated lines, got %q", content)
+	}
+}
+
+func TestLog_EscapeNewlines(t *testing.T) {
+	for _, format := range []int{FormatText, FormatJSON} {
+		dir := t.TempDir()
+		LogClient := NewLogger()
+		LogClient.SetFormat(format)
+		LogClient.SetLogger(Info, dir, 6)
+		LogClient.Infof("first line\nsecond line\r\nthird line")
+
+		content := readTodayLog(t, LogClient, dir)
+		LogClient.Close()
+		if strings.Count(content, "\n") != 1 || strings.Contains(content, "\r") {
+			t.Fatalf("format %d: expected a single physical line, got %q", format, content)
+		}
+		if !strings.Contains(content, `first line\nsecond line\r\nthird line`) {
+			t.Errorf("format %d: expected escaped newlines in message, got %q", format, content)
+		}
+	}
+}
+
+func TestLog_FileCheckThrottled(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	for i := 0; i < 50; i++ {
+		LogClient.Infof("throttled check %d", i)
+		LogClient.Flush()
+	}
+	if checks := atomic.LoadInt64(&LogClient.(*Log).fileChecks); checks > 2 {
+		t.Errorf("checked for a deleted file %d times for 50 writes, want at most 2", checks)
+	}
+}
+
+func TestLog_RecoverDeletedDir(t *testing.T) {
+	// 每次写入都检查文件是否还在
+	interval := fileCheckInterval
+	fileCheckInterval = 0
+	t.Cleanup(func() { fileCheckInterval = interval })
+	dir := filepath.Join(t.TempDir(), "logs")
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("before removal")
+	LogClient.Flush()
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	LogClient.Infof("after removal")
+
+	content := readTodayLog(t, LogClient, dir)
+	if !strings.Contains(content, "message:after removal\n") {
+		t.Errorf("expected logging to recover in the recreated directory, got %q", content)
+	}
+	LogClient.Infof("next line")
+	if content := readTodayLog(t, LogClient, dir); strings.Count(content, "\n") != 2 {
+		t.Errorf("expected following lines to go to the recreated file, got %q", content)
+	}
+}
+
+// 替换 openFile，failures 大于 0 时打开失败并减一
+func stubOpenFile(t *testing.T, failures *int32) *int32 {
+	t.Helper()
+	var attempts int32
+	openFile = func(name string, flag int, perm os.FileMode) (*os.File, error) {
+		atomic.AddInt32(&attempts, 1)
+		if atomic.AddInt32(failures, -1) >= 0 {
+			return nil, errors.New("transient open failure")
+		}
+		return os.OpenFile(name, flag, perm)
+	}
+	t.Cleanup(func() { openFile = os.OpenFile })
+	return &attempts
+}
+
+func TestLog_OpenRetry(t *testing.T) {
+	dir := t.TempDir()
+	var failures int32
+	attempts := stubOpenFile(t, &failures)
+	LogClient := NewLogger()
+	LogClient.SetMaxSize(100)
+	LogClient.SetLogger(Info, dir, 6)
+	atomic.StoreInt32(&failures, 1)
+	atomic.StoreInt32(attempts, 0)
+	LogClient.Infof("first file message %s", strings.Repeat("x", 60))
+	LogClient.Infof("second file message %s", strings.Repeat("x", 60))
+	LogClient.Close()
+
+	if got := atomic.LoadInt32(attempts); got != 2 {
+		t.Errorf("expected one failed and one successful open, got %d attempts", got)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, defaultFileNamePattern.fileName(time.Now(), 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "second file message") {
+		t.Errorf("expected the retried file to receive the line, got %q", data)
+	}
+}
+
+func TestLog_OpenFailureFallsBackToStderr(t *testing.T) {
+	dir := t.TempDir()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = writer
+	defer func() { os.Stderr = stderr }()
+	var failures int32
+	stubOpenFile(t, &failures)
+
+	LogClient := NewLogger()
+	LogClient.SetMaxSize(100)
+	var reported int32
+	LogClient.SetInternalErrorHandler(func(err error) {
+		if strings.HasPrefix(err.Error(), "open log file: ") {
+			atomic.AddInt32(&reported, 1)
+		}
+	})
+	LogClient.SetLogger(Info, dir, 6)
+	atomic.StoreInt32(&failures, openAttempts)
+	LogClient.Infof("first file message %s", strings.Repeat("x", 60))
+	LogClient.Infof("fallback message %s", strings.Repeat("x", 60))
+	LogClient.Close()
+	os.Stderr = stderr
+	_ = writer.Close()
+	console, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(&reported) != 1 {
+		t.Errorf("expected the open failure to be reported once, got %d", reported)
+	}
+	if !strings.Contains(string(console), "message:fallback message") {
+		t.Errorf("expected the line on stderr after the open failure, got %q", console)
+	}
+}
+
+func TestLog_OpenFailureRecovers(t *testing.T) {
+	dir := t.TempDir()
+	console, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = console
+	defer func() {
+		os.Stderr = stderr
+		_ = console.Close()
+	}()
+	var failures int32
+	stubOpenFile(t, &failures)
+
+	var mutex sync.Mutex
+	current := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	LogClient := NewLogger()
+	LogClient.(*Log).setClock(func() time.Time {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return current
+	})
+	LogClient.SetMaxSize(100)
+	LogClient.SetInternalErrorHandler(func(error) {})
+	LogClient.SetLogger(Info, dir, 0)
+	defer LogClient.Close()
+	atomic.StoreInt32(&failures, openAttempts)
+	LogClient.Infof("first file message %s", strings.Repeat("x", 60))
+	LogClient.Infof("fallback message %s", strings.Repeat("x", 60))
+	LogClient.Flush()
+
+	// 进入新的一天时重新尝试打开文件
+	mutex.Lock()
+	current = current.AddDate(0, 0, 1)
+	mutex.Unlock()
+	LogClient.Infof("next day message")
+	LogClient.Flush()
+	data, err := os.ReadFile(filepath.Join(dir, "2024-03-02.log"))
+	if err != nil || !strings.Contains(string(data), "next day message") {
+		t.Errorf("expected the next period to reopen a file, got %q (%v)", data, err)
+	}
+
+	// 仍在失败时 Rotate 返回错误，恢复后 Reopen 重新打开文件
+	atomic.StoreInt32(&failures, openAttempts)
+	if err := LogClient.Rotate(); err == nil {
+		t.Error("expected Rotate to report the open failure")
+	}
+	atomic.StoreInt32(&failures, openAttempts)
+	if err := LogClient.Rotate(); err == nil {
+		t.Error("expected Rotate to retry and report the open failure again")
+	}
+	atomic.StoreInt32(&failures, 0)
+	if err := LogClient.Reopen(); err != nil {
+		t.Fatalf("expected Reopen to leave the stderr fallback, got %v", err)
+	}
+	LogClient.Infof("after reopen")
+	LogClient.Flush()
+	data, err = os.ReadFile(filepath.Join(dir, defaultFileNamePattern.fileName(current, 1)))
+	if err != nil || !strings.Contains(string(data), "after reopen") {
+		t.Errorf("expected the line in a reopened file, got %q (%v)", data, err)
+	}
+}
+
+func TestLog_MaxMessageBytes(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetMaxMessageBytes(64)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("payload %s", strings.Repeat("x", 1<<20))
+	LogClient.Infof("short message")
+
+	content := readTodayLog(t, LogClient, dir)
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	want := "message:payload " + strings.Repeat("x", 64-len("payload ")) + truncatedMarker
+	if !strings.HasSuffix(lines[0], want) {
+		t.Errorf("expected truncated message, got %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "message:short message") {
+		t.Errorf("short message should not be truncated, got %q", lines[1])
+	}
+	if got := LogClient.(*Log).truncateMessage(strings.Repeat("日", 30)); !utf8.ValidString(got) {
+		t.Errorf("truncation split a multi-byte character: %q", got)
+	}
+}
+
+func TestLog_UseUTC(t *testing.T) {
+	// 使用与 UTC 相差 14 小时的本地时区，保证本地日期与 UTC 日期大概率不同
+	local := time.Local
+	time.Local = time.FixedZone("UTC+14", 14*60*60)
+	defer func() { time.Local = local }()
+
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetUseUTC(true)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("utc message")
+	LogClient.Flush()
+
+	if _, err := os.Stat(filepath.Join(dir, formatLogFileName(time.Now().UTC()))); err != nil {
+		t.Errorf("expected file named after the UTC date: %v", err)
+	}
+}
+
+func TestLog_FileNamePattern(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().AddDate(0, 0, -30)
+	pattern := FileNamePattern{Prefix: "app-"}
+	oldPath := filepath.Join(dir, "app-"+old.Format("2006-01-02")+".log")
+	otherPath := filepath.Join(dir, "other.log")
+	for _, path := range []string{oldPath, otherPath} {
+		if err := os.WriteFile(path, []byte("old\n"), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	LogClient := NewLogger()
+	LogClient.SetFileNamePattern(pattern)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("pattern message")
+	LogClient.Flush()
+	if err := LogClient.(*Log).clearOldLogs(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "app-"+time.Now().Format("2006-01-02")+".log"))
+	if err != nil || !strings.Contains(string(data), "pattern message") {
+		t.Errorf("expected message in prefixed file: %v %q", err, data)
+	}
+	if _, err = os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("expired prefixed file should be removed: %v", err)
+	}
+	if _, err = os.Stat(otherPath); err != nil {
+		t.Errorf("file not matching the pattern should be kept: %v", err)
+	}
+}
+
+func TestLog_RotateHourly(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetRotateInterval(RotateHourly)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	l := LogClient.(*Log)
+	if want := time.Now().Format(hourlyLayout); l.currentDate != want {
+		t.Errorf("currentDate = %q, want %q", l.currentDate, want)
+	}
+
+	// 模拟当前文件属于上一个小时
+	file := l.currentFile
+	l.currentDate = time.Now().Add(-time.Hour).Format(hourlyLayout)
+	LogClient.Infof("hourly message")
+	LogClient.Flush()
+
+	if l.currentFile == file {
+		t.Error("expected a new file after the hour boundary")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, time.Now().Format(hourlyLayout)+".log"))
+	if err != nil || !strings.Contains(string(data), "hourly message") {
+		t.Errorf("expected message in hourly file: %v %q", err, data)
+	}
+}
+
+func TestLog_RotateMonthly(t *testing.T) {
+	dir := t.TempDir()
+	var mutex sync.Mutex
+	current := time.Date(2024, 1, 31, 23, 59, 59, 0, time.Local)
+	LogClient := NewLogger()
+	LogClient.(*Log).setClock(func() time.Time {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return current
+	})
+	LogClient.SetRotateInterval(RotateMonthly)
+	LogClient.SetLogger(Info, dir, 0)
+	defer LogClient.Close()
+	LogClient.Infof("january message")
+	LogClient.Flush()
+	mutex.Lock()
+	current = current.Add(2 * time.Second)
+	mutex.Unlock()
+	LogClient.Infof("february message")
+	LogClient.Flush()
+
+	for name, want := range map[string]string{
+		"2024-01.log": "january message",
+		"2024-02.log": "february message",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), want) || strings.Count(string(data), "\n") != 1 {
+			t.Errorf("%s = %q, want only %q", name, data, want)
+		}
+	}
+}
+
+func TestLog_GetConfig(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetMaxSize(1024)
+	LogClient.SetLogger(Warn, dir, 3)
+	defer LogClient.Close()
+
+	want := Config{
+		Level:           Warn,
+		FilePath:        dir,
+		MaxDay:          3,
+		MaxSize:         1024,
+		DirPerm:         defaultDirPerm,
+		FilePerm:        defaultFilePerm,
+		TimeFormat:      defaultTimeFormat,
+		FileNamePattern: defaultFileNamePattern,
+		BufferSize:      defaultBufferSize,
+		LineTerminator:  defaultLineTerminator,
+	}
+	if got := LogClient.GetConfig(); got != want {
+		t.Errorf("GetConfig() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLog_ConfigRoundTrip(t *testing.T) {
+	// 所有配置项都取非默认值，GetConfig 应原样返回，保证其结果可以重新用于创建日志
+	want := Config{
+		Level:             Warn,
+		FilePath:          t.TempDir(),
+		MaxDay:            3,
+		MaxSize:           1024,
+		MaxBackups:        2,
+		MaxTotalSize:      4096,
+		CallerSkip:        1,
+		Format:            FormatJSON,
+		Compress:          true,
+		DirPerm:           0700,
+		FilePerm:          0600,
+		TimeFormat:        time.RFC3339,
+		UseUTC:            true,
+		FileNamePattern:   FileNamePattern{Prefix: "app-", Layout: "2006-01-02", Suffix: ".log"},
+		BufferSize:        10,
+		FlushInterval:     time.Hour,
+		SendTimeout:       time.Second,
+		DedupTimeout:      time.Minute,
+		ShortCaller:       true,
+		DisableCaller:     true,
+		SymlinkCurrent:    true,
+		ShowGoroutineID:   true,
+		CompressOnRotate:  true,
+		SeparateErrorFile: true,
+		LineTerminator:    "\r\n",
+		MaxMessageBytes:   256,
+		Color:             true,
+		ForceColor:        true,
+		FullFuncName:      true,
+		ShowSequence:      true,
+		RingBufferSize:    5,
+		SyncOnError:       true,
+		BackupNameScheme:  BackupNameTimestamp,
+		LevelStyle:        LevelStyleShort,
+	}
+	LogClient, err := NewLoggerWithConfig(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer LogClient.Close()
+	if got := LogClient.GetConfig(); got != want {
+		t.Errorf("GetConfig() = %+v, want %+v", got, want)
+	}
+	if _, err := NewLoggerWithConfig(Config{FilePath: t.TempDir(), SendTimeout: -time.Second}); err == nil {
+		t.Error("expected an error for a negative send timeout")
+	}
+	if _, err := NewLoggerWithConfig(Config{FilePath: t.TempDir(), LevelStyle: 42}); err == nil {
+		t.Error("expected an error for an invalid level style")
+	}
+	if _, err := NewLoggerWithConfig(Config{FilePath: t.TempDir(), BackupNameScheme: 42}); err == nil {
+		t.Error("expected an error for an invalid backup name scheme")
+	}
+	if _, err := NewLoggerWithConfig(Config{FilePath: t.TempDir(), RingBufferSize: -1}); err == nil {
+		t.Error("expected an error for a negative ring buffer size")
+	}
+	if _, err := NewLoggerWithConfig(Config{FilePath: t.TempDir(), MaxMessageBytes: -1}); err == nil {
+		t.Error("expected an error for a negative max message bytes")
+	}
+	if _, err := NewLoggerWithConfig(Config{FilePath: t.TempDir(), DedupTimeout: -time.Second}); err == nil {
+		t.Error("expected an error for a negative dedup timeout")
+	}
+}
+
+func TestLog_ConcurrentSetLevel(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				LogClient.Infof("concurrent message %d", j)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				LogClient.SetLevel(Debug + (i+j)%4)
+				_ = LogClient.GetConfig()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestLog_IsLevelEnabled(t *testing.T) {
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, t.TempDir(), 6)
+	defer LogClient.Close()
+	if LogClient.IsLevelEnabled(Debug) {
+		t.Error("Debug should be disabled at Info level")
+	}
+	if !LogClient.IsLevelEnabled(Info) || !LogClient.IsLevelEnabled(Error) {
+		t.Error("Info and Error should be enabled at Info level")
+	}
+	LogClient.SetLevel(Debug)
+	if !LogClient.IsLevelEnabled(Debug) {
+		t.Error("Debug should be enabled after SetLevel(Debug)")
+	}
+}
+
+func TestLog_SetLevelAtRuntime(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	file := LogClient.(*Log).currentFile
+	LogClient.Debugf("debug before")
+	LogClient.Infof("info before")
+	LogClient.SetLevel(Debug)
+	LogClient.Debugf("debug after")
+
+	content := readTodayLog(t, LogClient, dir)
+	if strings.Contains(content, "debug before") {
+		t.Errorf("debug line written before lowering the level: %q", content)
+	}
+	if !strings.Contains(content, "info before") || !strings.Contains(content, "debug after") {
+		t.Errorf("expected info and newly enabled debug lines: %q", content)
+	}
+	if LogClient.(*Log).currentFile != file {
+		t.Error("SetLevel should not reopen the log file")
+	}
+}
+
+func TestNewLoggerWithConfig(t *testing.T) {
+	dir := t.TempDir()
+	LogClient, err := NewLoggerWithConfig(Config{FilePath: dir, Format: FormatJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer LogClient.Close()
+	LogClient.Infof("config message")
+
+	content := readTodayLog(t, LogClient, dir)
+	if !strings.Contains(content, `"message":"config message"`) {
+		t.Errorf("config message missing: %q", content)
+	}
+	if conf := LogClient.GetConfig(); conf.Level != Info || conf.MaxDay != 7 {
+		t.Errorf("defaults not applied: %+v", conf)
+	}
+
+	// 负数的 MaxDay 与 SetLogger 一致，表示永久保留
+	keep, err := NewLoggerWithConfig(Config{FilePath: t.TempDir(), MaxDay: -1})
+	if err != nil {
+		t.Fatalf("expected a negative MaxDay to be accepted: %v", err)
+	}
+	conf := keep.GetConfig()
+	if conf.MaxDay != -1 {
+		t.Errorf("expected keep-forever to be reported as MaxDay -1, got %d", conf.MaxDay)
+	}
+	keep.Close()
+	// GetConfig 的结果重新用于创建日志时仍然永久保留
+	conf.FilePath = t.TempDir()
+	again, err := NewLoggerWithConfig(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := again.GetConfig().MaxDay; got != -1 {
+		t.Errorf("keep-forever did not survive a config round trip, got MaxDay %d", got)
+	}
+	again.Close()
+
+	if _, err = NewLoggerWithConfig(Config{FilePath: dir, Level: 42}); err == nil {
+		t.Error("expected an error for an invalid level")
+	}
+}
+
+func TestLog_LogBeforeSetLogger(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = writer
+	defer func() { os.Stderr = stderr }()
+
+	LogClient := NewLogger()
+	LogClient.Infof("early message")
+	LogClient.Close()
+	os.Stderr = stderr
+	_ = writer.Close()
+
+	console, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(console), "early message") {
+		t.Errorf("expected early message on stderr: %q", console)
+	}
+}
+
+// 写入时阻塞直到 release 被关闭，用于模拟卡住的写入协程
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
+
+func TestLog_DropWhenFull(t *testing.T) {
+	writer := &blockingWriter{release: make(chan struct{})}
+	LogClient := NewLogger()
+	LogClient.SetOutput(writer)
+	LogClient.SetDropWhenFull(true)
+	LogClient.SetLogger(Info, t.TempDir(), 6)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3100; i++ {
+			LogClient.Infof("burst message %d", i)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sends blocked with DropWhenFull enabled")
+	}
+	if dropped := LogClient.DroppedCount(); dropped < 99 {
+		t.Errorf("DroppedCount() = %d, want at least 99", dropped)
+	}
+	close(writer.release)
+	LogClient.Close()
+}
+
+func TestLog_SendTimeout(t *testing.T) {
+	writer := &blockingWriter{release: make(chan struct{})}
+	LogClient := NewLogger()
+	LogClient.SetOutput(writer)
+	LogClient.SetBufferSize(1)
+	LogClient.SetSendTimeout(20 * time.Millisecond)
+	LogClient.SetLogger(Info, t.TempDir(), 6)
+	defer func() {
+		close(writer.release)
+		LogClient.Close()
+	}()
+
+	// 写入协程阻塞在第一条日志上，第二条占满通道，之后的日志等待超时后丢弃
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		LogClient.Infof("stalled message %d", i)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("sends did not unblock after the timeout, took %v", elapsed)
+	} else if elapsed < 20*time.Millisecond {
+		t.Errorf("sends should wait for the timeout before dropping, took %v", elapsed)
+	}
+	if dropped := LogClient.DroppedCount(); dropped < 3 {
+		t.Errorf("DroppedCount() = %d, want at least 3", dropped)
+	}
+}
+
+func TestLog_BufferSize(t *testing.T) {
+	writer := &blockingWriter{release: make(chan struct{})}
+	LogClient := NewLogger()
+	LogClient.SetOutput(writer)
+	LogClient.SetDropWhenFull(true)
+	LogClient.SetBufferSize(10)
+	LogClient.SetLogger(Info, t.TempDir(), 6)
+	defer func() {
+		close(writer.release)
+		LogClient.Close()
+	}()
+
+	for i := 0; i < 20; i++ {
+		LogClient.Infof("buffered message %d", i)
+	}
+	// 通道容量为 10，写入协程最多再取走一条阻塞在 Write 上
+	if sent := 20 - LogClient.DroppedCount(); sent < 10 || sent > 11 {
+		t.Errorf("%d sends accepted, want 10 or 11", sent)
+	}
+}
+
+func TestLog_FlushInterval(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetFlushInterval(20 * time.Millisecond)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("buffered message")
+
+	// 不调用 Flush，等待定时刷新写入文件
+	path := filepath.Join(dir, formatLogFileName(time.Now()))
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, _ := os.ReadFile(path)
+		if strings.Contains(string(data), "buffered message") {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("buffered message was not flushed: %q", data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func benchmarkInfof(b *testing.B, flushInterval time.Duration) {
+	benchmarkInfofWith(b, func(l Logger) { l.SetFlushInterval(flushInterval) })
+}
+
+func benchmarkInfofWith(b *testing.B, configure func(l Logger)) {
+	LogClient := NewLogger()
+	configure(LogClient)
+	LogClient.SetLogger(Info, b.TempDir(), 6)
+	defer LogClient.Close()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		LogClient.Infof("benchmark message %d", i)
+	}
+	LogClient.Flush()
+}
+
+func BenchmarkLog_Unbuffered(b *testing.B) {
+	benchmarkInfof(b, 0)
+}
+
+func BenchmarkLog_Buffered(b *testing.B) {
+	benchmarkInfof(b, 100*time.Millisecond)
+}
+
+func BenchmarkLog_WithCaller(b *testing.B) {
+	benchmarkInfofWith(b, func(l Logger) { l.SetDisableCaller(false) })
+}
+
+func BenchmarkLog_DisableCaller(b *testing.B) {
+	benchmarkInfofWith(b, func(l Logger) { l.SetDisableCaller(true) })
+}
+
+func TestLog_InfofNoArgs(t *testing.T) {
+	for _, format := range []string{"constant message", "100%% done", "bad %d verb", ""} {
+		if got, want := formatMessage(format, nil), fmt.Sprintf(format); got != want {
+			t.Errorf("formatMessage(%q) = %q, want %q", format, got, want)
+		}
+	}
+	if allocs := testing.AllocsPerRun(100, func() { formatMessage("constant message", nil) }); allocs != 0 {
+		t.Errorf("expected no allocations for a constant message, got %v", allocs)
+	}
+
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetDisableCaller(true)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("constant message")
+	LogClient.Infof("%s", "constant message")
+	content := readTodayLog(t, LogClient, dir)
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", content)
+	}
+	for _, line := range lines {
+		if !strings.HasSuffix(line, "] message:constant message") {
+			t.Errorf("expected identical messages with and without args, got %q", line)
+		}
+	}
+}
+
+func BenchmarkFormatMessage_NoArgs(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		formatMessage("benchmark message", nil)
+	}
+}
+
+func BenchmarkFormatMessage_Sprintf(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = fmt.Sprintf("benchmark message")
+	}
+}
+
+func TestLog_Rotate(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	LogClient.Infof("before rotate")
+	if err := LogClient.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	LogClient.Infof("after rotate")
+	LogClient.Close()
+
+	now := time.Now()
+	for name, want := range map[string]string{
+		defaultFileNamePattern.fileName(now, 0): "before rotate",
+		defaultFileNamePattern.fileName(now, 1): "after rotate",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), want) || strings.Count(string(data), "\n") != 1 {
+			t.Errorf("%s = %q, want only %q", name, data, want)
+		}
+	}
+	if rotations := LogClient.Stats().Rotations; rotations != 1 {
+		t.Errorf("expected 1 rotation, got %d", rotations)
+	}
+}
+
+func TestLog_RotateTimestampBackup(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetBackupNameScheme(BackupNameTimestamp)
+	LogClient.SetLogger(Info, dir, 6)
+	LogClient.Infof("before rotate")
+	if err := LogClient.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	LogClient.Infof("after rotate")
+	LogClient.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, defaultBackupBaseName+defaultFileNamePattern.Suffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "after rotate") || strings.Contains(string(data), "before rotate") {
+		t.Errorf("current file = %q, want only the line after rotate", data)
+	}
+	backups, err := filepath.Glob(filepath.Join(dir, defaultBackupBaseName+"-*"+defaultFileNamePattern.Suffix))
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("expected 1 backup, got %v (%v)", backups, err)
+	}
+	data, err = os.ReadFile(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "before rotate") || strings.Contains(string(data), "after rotate") {
+		t.Errorf("backup = %q, want only the line before rotate", data)
+	}
+}
+
+func TestLog_WriterActionsAfterClose(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	LogClient.Close()
+	if err := LogClient.Reopen(); err == nil {
+		t.Error("expected Reopen after Close to return an error")
+	}
+	if err := LogClient.Rotate(); err == nil {
+		t.Error("expected Rotate after Close to return an error")
+	}
+	if err := LogClient.SetPath(t.TempDir()); err == nil {
+		t.Error("expected SetPath after Close to return an error")
+	}
+}
+
+func TestLog_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("before rotate")
+	LogClient.Flush()
+
+	path := filepath.Join(dir, formatLogFileName(time.Now()))
+	rotated := path + ".1"
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	if err := LogClient.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	LogClient.Infof("after rotate")
+
+	content := readTodayLog(t, LogClient, dir)
+	if strings.Contains(content, "before rotate") || !strings.Contains(content, "after rotate") {
+		t.Errorf("unexpected content in reopened file: %q", content)
+	}
+	data, err := os.ReadFile(rotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "before rotate") || strings.Contains(string(data), "after rotate") {
+		t.Errorf("unexpected content in rotated file: %q", data)
+	}
+}
+
+func TestLog_SetPath(t *testing.T) {
+	oldDir := t.TempDir()
+	newDir := filepath.Join(t.TempDir(), "moved")
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, oldDir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("before move")
+	if err := LogClient.SetPath(newDir); err != nil {
+		t.Fatal(err)
+	}
+	LogClient.Infof("after move")
+
+	if content := readTodayLog(t, LogClient, oldDir); !strings.Contains(content, "before move") || strings.Contains(content, "after move") {
+		t.Errorf("unexpected content in old directory: %q", content)
+	}
+	if content := readTodayLog(t, LogClient, newDir); strings.Contains(content, "before move") || !strings.Contains(content, "after move") {
+		t.Errorf("unexpected content in new directory: %q", content)
+	}
+	if conf := LogClient.GetConfig(); conf.FilePath != newDir {
+		t.Errorf("FilePath = %q, want %q", conf.FilePath, newDir)
+	}
+}
+
+func TestLog_CloseWithTimeout(t *testing.T) {
+	writer := &blockingWriter{release: make(chan struct{})}
+	defer close(writer.release)
+	LogClient := NewLogger()
+	LogClient.SetOutput(writer)
+	LogClient.SetLogger(Info, t.TempDir(), 6)
+	LogClient.Infof("stuck message")
+
+	start := time.Now()
+	if err := LogClient.CloseWithTimeout(50 * time.Millisecond); err == nil {
+		t.Error("expected timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("close took %v, want within deadline", elapsed)
+	}
+}
+
+func TestLog_CloseWithTimeoutDrained(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	LogClient.Infof("drained message")
+	if err := LogClient.CloseWithTimeout(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "drained message") {
+		t.Errorf("expected drained message, got %q", data)
+	}
+}
+
+func TestLog_CloseWithTimeoutStuckFile(t *testing.T) {
+	LogClient := NewLogger()
+	LogClient.SetFlushInterval(10 * time.Millisecond)
+	LogClient.SetLogger(Info, t.TempDir(), 6)
+	LogClient.Infof("buffered message")
+	// 写入文件卡住时写入协程和定时刷新都持有 l.mutex，这里直接持有它来模拟
+	LogClient.(*Log).mutex.Lock()
+
+	start := time.Now()
+	err := LogClient.CloseWithTimeout(100 * time.Millisecond)
+	elapsed := time.Since(start)
+	LogClient.(*Log).mutex.Unlock()
+	if err == nil {
+		t.Error("expected timeout error")
+	}
+	if elapsed > time.Second {
+		t.Errorf("close took %v, want within deadline", elapsed)
+	}
+	// 写入恢复后，后台的关闭随之结束
+	if err := LogClient.Close(); err != nil {
+		t.Errorf("Close after timeout = %v, want nil", err)
+	}
+}
+
+func TestLog_CloseReturnsError(t *testing.T) {
+	LogClient := NewLogger()
+	LogClient.SetFlushInterval(time.Hour)
+	LogClient.SetLogger(Info, t.TempDir(), 6)
+	LogClient.Infof("buffered before close")
+	LogClient.(*Log).pendingMutex.Lock()
+	for LogClient.(*Log).pending > 0 {
+		LogClient.(*Log).pendingCond.Wait()
+	}
+	LogClient.(*Log).pendingMutex.Unlock()
+	// 提前关闭底层文件，模拟磁盘写满等导致的刷新和关闭失败
+	_ = LogClient.(*Log).currentFile.Close()
+
+	if err := LogClient.Close(); err == nil {
+		t.Error("expected close error to propagate")
+	}
+}
+
+func TestLog_ContextCancelStopsWriter(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	LogClient := NewLoggerWithContext(ctx)
+	LogClient.SetFlushInterval(time.Hour)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	for i := 0; i < 100; i++ {
+		LogClient.Infof("before cancel %d", i)
+	}
+	cancel()
+
+	exited := make(chan struct{})
+	go func() {
+		LogClient.(*Log).writerWg.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("writer goroutine did not exit after cancel")
+	}
+	LogClient.Infof("after cancel")
+
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(data), "before cancel") != 100 {
+		t.Errorf("expected all buffered lines flushed, got %q", data)
+	}
+	if strings.Contains(string(data), "after cancel") {
+		t.Errorf("expected lines after cancel to be dropped, got %q", data)
+	}
+}
+
+func TestLog_BatchWrite(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	l := LogClient.(*Log)
+	// 先阻塞写入协程，使后续日志在通道中堆积，放行后按批写入
+	release := make(chan struct{})
+	l.addPending()
+	l.logChannels <- logLine{action: func() { <-release }}
+	const lines = 1000
+	for i := 0; i < lines; i++ {
+		LogClient.Infof("batch message %d", i)
+	}
+	close(release)
+
+	content := readTodayLog(t, LogClient, dir)
+	got := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(got) != lines {
+		t.Fatalf("expected %d lines, got %d", lines, len(got))
+	}
+	for i, line := range got {
+		if !strings.HasSuffix(line, fmt.Sprintf("message:batch message %d", i)) {
+			t.Fatalf("line %d out of order: %q", i, line)
+		}
+	}
+	if writes := atomic.LoadInt64(&l.fileWrites); writes > lines/maxBatchLines+1 {
+		t.Errorf("expected batched writes, got %d writes for %d lines", writes, lines)
+	}
+}
+
+func BenchmarkLog_BatchWrite(b *testing.B) {
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, b.TempDir(), 6)
+	defer LogClient.Close()
+	l := LogClient.(*Log)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		LogClient.Infof("benchmark message %d", i)
+	}
+	LogClient.Flush()
+	b.ReportMetric(float64(atomic.LoadInt64(&l.fileWrites))/float64(b.N), "writes/op")
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, level := range []int{Debug, Info, Warn, Error} {
+		got, err := ParseLevel(LevelString(level))
+		if err != nil || got != level {
+			t.Errorf("ParseLevel(LevelString(%d)) = %d, %v", level, got, err)
+		}
+	}
+	for name, want := range map[string]int{"debug": Debug, " INFO ": Info, "warning": Warn, "Error": Error} {
+		if got, err := ParseLevel(name); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %d, %v, want %d", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"", "verbose", "inf", "3"} {
+		if _, err := ParseLevel(name); err == nil {
+			t.Errorf("ParseLevel(%q) should fail", name)
+		}
+	}
+	if LevelString(0) != "" || LevelString(Error+1) != "" {
+		t.Error("expected empty names for invalid levels")
+	}
+}
@@ -0,0 +1,96 @@
+package Logger
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// 不输出任何内容的日志，用于测试或关闭日志的场景，调用方无需判断 nil
+type nopLogger struct{}
+
+// 创建空日志，所有方法都不做任何事，Fatalf 也不会退出进程，RecoverAndLog 只捕获 panic 而不记录
+func NewNopLogger() Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) SetLogger(Level int, FilePath string, MaxDay int64) error { return nil }
+func (nopLogger) Debugf(format string, a ...interface{})                   {}
+func (nopLogger) Errorf(format string, a ...interface{})                   {}
+func (nopLogger) ErrorfWithStack(format string, a ...interface{})          {}
+func (nopLogger) WriteEntry(e Entry)                                       {}
+func (nopLogger) Writer(level int) io.Writer                               { return io.Discard }
+func (nopLogger) Fatalf(format string, a ...interface{})                   {}
+func (nopLogger) RecoverAndLog(rethrow bool) {
+	if r := recover(); r != nil && rethrow {
+		panic(r)
+	}
+}
+func (nopLogger) Warnf(format string, a ...interface{})                          {}
+func (nopLogger) Infof(format string, a ...interface{})                          {}
+func (nopLogger) InfoRaw(msg string)                                             {}
+func (nopLogger) ErrorRaw(msg string)                                            {}
+func (nopLogger) InfofCtx(ctx context.Context, format string, a ...interface{})  {}
+func (nopLogger) ErrorfCtx(ctx context.Context, format string, a ...interface{}) {}
+func (nopLogger) SetMaxSize(MaxSize int64)                                       {}
+func (nopLogger) SetOutput(w io.Writer)                                          {}
+func (nopLogger) SetConsoleOutput(enable bool)                                   {}
+func (nopLogger) SetColor(enable bool)                                           {}
+func (nopLogger) SetForceColor(force bool)                                       {}
+func (nopLogger) SetCallerSkip(skip int)                                         {}
+func (nopLogger) SetShortCaller(enable bool)                                     {}
+func (nopLogger) SetFullFuncName(enable bool)                                    {}
+func (nopLogger) SetDisableCaller(disable bool)                                  {}
+func (nopLogger) SetSymlinkCurrent(enable bool)                                  {}
+func (nopLogger) SetShowGoroutineID(enable bool)                                 {}
+func (nopLogger) SetShowSequence(enable bool)                                    {}
+func (nopLogger) SetOnRotate(fn func(oldPath, newPath string))                   {}
+func (nopLogger) SetFormat(format int)                                           {}
+func (nopLogger) SetFormatter(f Formatter)                                       {}
+func (n nopLogger) WithFields(fields map[string]interface{}) Logger              { return n }
+func (n nopLogger) Named(name string) Logger                                     { return n }
+func (n nopLogger) WithError(err error) Logger                                   { return n }
+func (n nopLogger) WithTag(key, value string) Logger                             { return n }
+func (nopLogger) SetCompress(enable bool)                                        {}
+func (nopLogger) SetCompressOnRotate(enable bool)                                {}
+func (nopLogger) SetSeparateErrorFile(enable bool)                               {}
+func (nopLogger) SetSyncOnError(enable bool)                                     {}
+func (nopLogger) SetMaxBackups(MaxBackups int)                                   {}
+func (nopLogger) SetMaxTotalSize(MaxTotalSize int64)                             {}
+func (nopLogger) SetPermissions(DirPerm, FilePerm os.FileMode)                   {}
+func (nopLogger) SetTimeFormat(TimeFormat string)                                {}
+func (nopLogger) SetLineTerminator(terminator string)                            {}
+func (nopLogger) SetMaxMessageBytes(n int)                                       {}
+func (nopLogger) SetUseUTC(enable bool)                                          {}
+func (nopLogger) SetFileNamePattern(pattern FileNamePattern)                     {}
+func (nopLogger) SetRotateInterval(interval int)                                 {}
+func (nopLogger) SetBackupNameScheme(scheme int)                                 {}
+func (nopLogger) SetLevelStyle(style int)                                        {}
+func (nopLogger) SetDropWhenFull(enable bool)                                    {}
+func (nopLogger) SetSendTimeout(timeout time.Duration)                           {}
+func (nopLogger) SetBufferSize(size int)                                         {}
+func (nopLogger) SetFlushInterval(interval time.Duration)                        {}
+func (nopLogger) SetSyslog(network, addr, tag string) error                      { return nil }
+func (nopLogger) SetHTTPSink(cfg HTTPSinkConfig)                                 {}
+func (nopLogger) AddHook(h Hook)                                                 {}
+func (nopLogger) SetFilter(fn func(level int, msg string) bool)                  {}
+func (nopLogger) SetRateLimit(level int, perSecond float64, burst int)           {}
+func (nopLogger) SetDedup(timeout time.Duration)                                 {}
+func (nopLogger) Reopen() error                                                  { return nil }
+func (nopLogger) Rotate() error                                                  { return nil }
+func (nopLogger) SetPath(newPath string) error                                   { return nil }
+func (nopLogger) DroppedCount() int64                                            { return 0 }
+func (nopLogger) Stats() Stats                                                   { return Stats{} }
+func (nopLogger) ReadLast(n int) ([]string, error)                               { return nil, nil }
+func (nopLogger) SetRingBufferSize(size int)                                     {}
+func (nopLogger) SetInternalErrorHandler(fn func(error))                         {}
+func (nopLogger) DumpRecent() []string                                           { return nil }
+func (nopLogger) SetLevel(level int)                                             {}
+func (nopLogger) Level() int                                                     { return 0 }
+func (nopLogger) IsLevelEnabled(level int) bool                                  { return false }
+func (nopLogger) GetConf()                                                       {}
+func (nopLogger) GetConfig() Config                                              { return Config{} }
+func (nopLogger) Flush()                                                         {}
+func (nopLogger) Close() error                                                   { return nil }
+func (nopLogger) CloseWithTimeout(d time.Duration) error                         { return nil }
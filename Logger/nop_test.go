@@ -0,0 +1,119 @@
+package Logger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNopLogger(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewNopLogger()
+	buf := &syncBuffer{}
+	LogClient.SetOutput(buf)
+	LogClient.SetConsoleOutput(true)
+	LogClient.SetColor(true)
+	LogClient.SetForceColor(true)
+	LogClient.SetMaxSize(1)
+	LogClient.SetCallerSkip(1)
+	LogClient.SetShortCaller(true)
+	LogClient.SetFullFuncName(true)
+	LogClient.SetDisableCaller(true)
+	LogClient.SetSymlinkCurrent(true)
+	LogClient.SetShowGoroutineID(true)
+	LogClient.SetShowSequence(true)
+	LogClient.SetOnRotate(func(oldPath, newPath string) { t.Error("unexpected rotation") })
+	LogClient.SetFormat(FormatJSON)
+	LogClient.SetFormatter(TextFormatter{})
+	LogClient.SetCompress(true)
+	LogClient.SetCompressOnRotate(true)
+	LogClient.SetSeparateErrorFile(true)
+	LogClient.SetSyncOnError(true)
+	LogClient.SetMaxBackups(1)
+	LogClient.SetMaxTotalSize(1)
+	LogClient.SetPermissions(0700, 0600)
+	LogClient.SetTimeFormat(UnixTimeFormat)
+	LogClient.SetLineTerminator("\r\n")
+	LogClient.SetMaxMessageBytes(1)
+	LogClient.SetUseUTC(true)
+	LogClient.SetFileNamePattern(FileNamePattern{Prefix: "app-"})
+	LogClient.SetRotateInterval(RotateHourly)
+	LogClient.SetBackupNameScheme(BackupNameTimestamp)
+	LogClient.SetLevelStyle(LevelStyleUpper)
+	LogClient.SetDropWhenFull(true)
+	LogClient.SetSendTimeout(time.Millisecond)
+	LogClient.SetBufferSize(1)
+	LogClient.SetFlushInterval(time.Second)
+	LogClient.SetHTTPSink(HTTPSinkConfig{URL: "http://127.0.0.1:0"})
+	LogClient.AddHook(nil)
+	LogClient.SetFilter(nil)
+	LogClient.SetRateLimit(Info, 1, 1)
+	LogClient.SetDedup(time.Second)
+	LogClient.SetLevel(Debug)
+	if err := LogClient.SetSyslog("udp", "127.0.0.1:0", "nop"); err != nil {
+		t.Error(err)
+	}
+	if err := LogClient.SetLogger(Debug, dir, 6); err != nil {
+		t.Error(err)
+	}
+
+	LogClient.Debugf("nop %d", 1)
+	LogClient.Infof("nop %d", 1)
+	LogClient.InfoRaw("nop")
+	LogClient.ErrorRaw("nop")
+	LogClient.Warnf("nop %d", 1)
+	LogClient.Errorf("nop %d", 1)
+	LogClient.ErrorfWithStack("nop %d", 1)
+	LogClient.WriteEntry(Entry{Level: Error, Message: "nop"})
+	_, _ = LogClient.Writer(Info).Write([]byte("nop\n"))
+	LogClient.Fatalf("nop %d", 1)
+	func() {
+		defer LogClient.RecoverAndLog(false)
+		panic("nop panic")
+	}()
+	LogClient.InfofCtx(context.Background(), "nop %d", 1)
+	LogClient.ErrorfCtx(context.Background(), "nop %d", 1)
+	LogClient.WithFields(map[string]interface{}{"k": "v"}).Infof("nop")
+	LogClient.Named("nop").Errorf("nop")
+	LogClient.WithError(errors.New("nop")).Errorf("nop")
+	LogClient.WithTag("tenant", "nop").Infof("nop")
+	LogClient.GetConf()
+	LogClient.Flush()
+	if err := LogClient.Reopen(); err != nil {
+		t.Error(err)
+	}
+	if err := LogClient.Rotate(); err != nil {
+		t.Error(err)
+	}
+	if err := LogClient.SetPath(dir); err != nil {
+		t.Error(err)
+	}
+	LogClient.SetRingBufferSize(3)
+	LogClient.SetInternalErrorHandler(func(error) {})
+	if LogClient.DumpRecent() != nil {
+		t.Error("expected no recent lines from nop logger")
+	}
+	if lines, err := LogClient.ReadLast(3); err != nil || lines != nil {
+		t.Error("expected no lines from nop logger")
+	}
+	if LogClient.IsLevelEnabled(Error) {
+		t.Error("expected no level to be enabled on nop logger")
+	}
+	if LogClient.DroppedCount() != 0 || LogClient.Level() != 0 || LogClient.GetConfig() != (Config{}) || LogClient.Stats() != (Stats{}) {
+		t.Error("expected zero values from nop logger")
+	}
+	if err := LogClient.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := LogClient.CloseWithTimeout(time.Second); err != nil {
+		t.Error(err)
+	}
+
+	if buf.String() != "" {
+		t.Errorf("expected no output, got %q", buf.String())
+	}
+	if allocs := testing.AllocsPerRun(100, func() { LogClient.Infof("nop") }); allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
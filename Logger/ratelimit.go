@@ -0,0 +1,103 @@
+package Logger
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 输出限流丢弃条数的间隔
+var rateSummaryInterval = time.Second
+
+// 令牌桶，每秒补充 rate 个令牌，最多积累 burst 个
+type tokenBucket struct {
+	rate       float64
+	burst      float64
+	tokens     float64
+	last       time.Time // 上次补充令牌的时间，零值表示尚未使用，由第一次 allow 按日志的时钟设置
+	suppressed int64     // 上次输出汇总后被丢弃的条数
+}
+
+// 按日志级别限流，未设置限流的级别不受影响
+type rateLimiter struct {
+	mutex   sync.Mutex
+	buckets map[int]*tokenBucket
+}
+
+// 设置某个级别每秒最多写入 perSecond 条，允许突发 burst 条，perSecond 小于等于 0 时取消该级别的限流
+func (l *Log) SetRateLimit(level int, perSecond float64, burst int) {
+	l.limiter.set(level, perSecond, burst)
+}
+
+func (r *rateLimiter) set(level int, perSecond float64, burst int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if perSecond <= 0 {
+		delete(r.buckets, level)
+		return
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	if r.buckets == nil {
+		r.buckets = make(map[int]*tokenBucket)
+	}
+	r.buckets[level] = &tokenBucket{rate: perSecond, burst: float64(burst), tokens: float64(burst)}
+}
+
+// 判断该级别的日志是否允许写入，不允许时计入丢弃条数
+func (r *rateLimiter) allow(level int, now time.Time) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	bucket, ok := r.buckets[level]
+	if !ok {
+		return true
+	}
+	// 时钟回拨时不补充也不扣除令牌
+	if elapsed := now.Sub(bucket.last); !bucket.last.IsZero() && elapsed > 0 {
+		bucket.tokens += elapsed.Seconds() * bucket.rate
+	}
+	if bucket.tokens > bucket.burst {
+		bucket.tokens = bucket.burst
+	}
+	bucket.last = now
+	if bucket.tokens < 1 {
+		bucket.suppressed++
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// 取出各级别被丢弃的条数并清零
+func (r *rateLimiter) takeSuppressed() map[int]int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var suppressed map[int]int64
+	for level, bucket := range r.buckets {
+		if bucket.suppressed == 0 {
+			continue
+		}
+		if suppressed == nil {
+			suppressed = make(map[int]int64)
+		}
+		suppressed[level] = bucket.suppressed
+		bucket.suppressed = 0
+	}
+	return suppressed
+}
+
+// 在写入协程中输出被限流丢弃的条数
+func (l *Log) writeSuppressed() {
+	suppressed := l.limiter.takeSuppressed()
+	levels := make([]int, 0, len(suppressed))
+	for level := range suppressed {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+	for _, level := range levels {
+		msg := fmt.Sprintf("%d %s messages suppressed", suppressed[level], l.GetLevelString(level))
+		l.writeLine(l.newLogLine(level, nil, nil, msg))
+	}
+}
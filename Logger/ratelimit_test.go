@@ -0,0 +1,73 @@
+package Logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLog_SetRateLimit(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	LogClient.SetRateLimit(Error, 0.001, 5)
+	for i := 0; i < 1000; i++ {
+		LogClient.Errorf("flood message %d", i)
+	}
+	LogClient.Infof("info message")
+	LogClient.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if count := strings.Count(content, "flood message"); count != 5 {
+		t.Errorf("got %d flood messages, want 5", count)
+	}
+	if !strings.Contains(content, "info message") {
+		t.Errorf("levels without a limit should not be affected: %q", content)
+	}
+	if !strings.Contains(content, "message:995 Error messages suppressed") {
+		t.Errorf("suppression summary missing: %q", content)
+	}
+}
+
+func TestLog_RateLimitUsesClock(t *testing.T) {
+	dir := t.TempDir()
+	var mutex sync.Mutex
+	current := time.Date(2001, 1, 1, 12, 0, 0, 0, time.Local)
+	LogClient := NewLogger()
+	LogClient.(*Log).setClock(func() time.Time {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return current
+	})
+	LogClient.SetLogger(Info, dir, 0)
+	LogClient.SetRateLimit(Error, 1, 2)
+	for i := 0; i < 3; i++ {
+		LogClient.Errorf("burst message %d", i)
+	}
+	mutex.Lock()
+	current = current.Add(time.Second)
+	mutex.Unlock()
+	LogClient.Errorf("refilled message")
+	LogClient.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, formatLogFileName(current)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{"burst message 0", "burst message 1", "refilled message"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q with an injected clock, got %q", want, content)
+		}
+	}
+	if strings.Contains(content, "burst message 2") {
+		t.Errorf("expected the third burst message to be suppressed, got %q", content)
+	}
+}
@@ -0,0 +1,28 @@
+package Logger
+
+// 不查找调用处、不格式化的 Info 级别日志，适合高频且不关心位置的场景
+func (l *Log) InfoRaw(msg string) {
+	l.writeRaw(Info, nil, msg)
+}
+
+// 不查找调用处、不格式化的 Error 级别日志
+func (l *Log) ErrorRaw(msg string) {
+	l.writeRaw(Error, nil, msg)
+}
+
+func (d *derivedLogger) InfoRaw(msg string) {
+	d.writeRaw(Info, &d.ctx, msg)
+}
+
+func (d *derivedLogger) ErrorRaw(msg string) {
+	d.writeRaw(Error, &d.ctx, msg)
+}
+
+// 只组装级别、时间和消息，与其他方法共用写入通道和文件切换
+func (l *Log) writeRaw(level int, ctx *logContext, msg string) {
+	if level < l.Level() || !l.limiter.allow(level, l.clockNow()) {
+		return
+	}
+	msg = l.truncateMessage(msg)
+	l.enqueue(l.newLogLine(level, ctx, nil, msg))
+}
@@ -0,0 +1,52 @@
+package Logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLog_Raw(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.InfoRaw("raw info 100%")
+	LogClient.Named("db").ErrorRaw("raw error")
+
+	content := readTodayLog(t, LogClient, dir)
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", content)
+	}
+	if !strings.HasPrefix(lines[0], "[Info][") || !strings.HasSuffix(lines[0], "] message:raw info 100%") {
+		t.Errorf("unexpected raw info line %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "[Error][") || !strings.HasSuffix(lines[1], "][db] message:raw error") {
+		t.Errorf("unexpected raw error line %q", lines[1])
+	}
+	if strings.Contains(content, "fileLine:") {
+		t.Errorf("raw lines should not contain caller info: %q", content)
+	}
+}
+
+func BenchmarkLog_Infof(b *testing.B) {
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, b.TempDir(), 6)
+	defer LogClient.Close()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		LogClient.Infof("benchmark message")
+	}
+	LogClient.Flush()
+}
+
+func BenchmarkLog_InfoRaw(b *testing.B) {
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, b.TempDir(), 6)
+	defer LogClient.Close()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		LogClient.InfoRaw("benchmark message")
+	}
+	LogClient.Flush()
+}
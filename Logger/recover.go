@@ -0,0 +1,66 @@
+package Logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// 捕获 panic 并以 Error 级别记录 panic 的值和调用栈，rethrow 为 true 时记录后重新 panic。
+// 需要直接通过 defer 调用：defer LogClient.RecoverAndLog(false)
+func (l *Log) RecoverAndLog(rethrow bool) {
+	if r := recover(); r != nil {
+		l.logPanic(nil, r)
+		if rethrow {
+			panic(r)
+		}
+	}
+}
+
+func (d *derivedLogger) RecoverAndLog(rethrow bool) {
+	if r := recover(); r != nil {
+		d.logPanic(&d.ctx, r)
+		if rethrow {
+			panic(r)
+		}
+	}
+}
+
+// 从 logPanic 到 runtime 中引发 panic 的栈帧之间的日志内部栈帧数：logPanic -> RecoverAndLog
+const panicDepth = 2
+
+// 记录 panic 的值和调用栈，调用处为引发 panic 的位置
+func (l *Log) logPanic(ctx *logContext, r interface{}) {
+	if Error < l.Level() {
+		return
+	}
+	// 调用栈比 ErrorfWithStack 多一层 RecoverAndLog
+	msg := fmt.Sprintf("panic: %v", r) + callerStack(l.CallerSkip+1)
+	l.enqueue(l.newLogLine(Error, ctx, l.lookupPanicCaller(panicDepth), msg))
+	// panic 往往意味着进程即将退出，等待日志写入文件
+	l.Flush()
+}
+
+// 查找引发 panic 的位置，跳过 depth 层日志内部栈帧和 runtime 包中处理 panic 的栈帧
+func (l *Log) lookupPanicCaller(depth int) *callerInfo {
+	if l.DisableCaller {
+		return nil
+	}
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(depth+2+l.CallerSkip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			file := frame.File
+			if l.ShortCaller {
+				file = filepath.Base(file)
+			}
+			return &callerInfo{file: file, line: frame.Line, funcName: l.funcName(frame.Function)}
+		}
+		if !more {
+			return &callerInfo{file: unknownCaller, line: 0, funcName: unknownCaller}
+		}
+	}
+}
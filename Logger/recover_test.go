@@ -0,0 +1,51 @@
+package Logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func panicWithRecover(LogClient Logger, rethrow bool) {
+	defer LogClient.RecoverAndLog(rethrow)
+	var values map[string]int
+	values["boom"]++
+}
+
+func TestLog_RecoverAndLog(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	panicWithRecover(LogClient, false)
+
+	content := readTodayLog(t, LogClient, dir)
+	if !strings.Contains(content, "[Error]") || !strings.Contains(content, "funcName:panicWithRecover;message:panic: assignment to entry in nil map\\n--- stack ---\\n") {
+		t.Fatalf("expected panic value with caller, got %q", content)
+	}
+	stack := content[strings.Index(content, "--- stack ---"):strings.Index(content, "--- end stack ---")]
+	if !strings.Contains(stack, "panicWithRecover") || !strings.Contains(stack, "TestLog_RecoverAndLog") {
+		t.Errorf("expected panicking call chain in stack, got %q", stack)
+	}
+	if strings.Contains(stack, "logPanic") || strings.Contains(stack, "callerStack") {
+		t.Errorf("expected logger frames to be trimmed, got %q", stack)
+	}
+}
+
+func TestLog_RecoverAndLogRethrow(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected the panic to be rethrown")
+			}
+		}()
+		panicWithRecover(LogClient.Named("handler"), true)
+	}()
+
+	if content := readTodayLog(t, LogClient, dir); !strings.Contains(content, "[handler]") || !strings.Contains(content, "panic: assignment to entry in nil map") {
+		t.Errorf("expected rethrown panic to be logged, got %q", content)
+	}
+}
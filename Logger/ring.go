@@ -0,0 +1,72 @@
+package Logger
+
+import (
+	"strings"
+	"sync"
+)
+
+// 保存最近若干条日志的环形缓冲区，容量固定，写满后覆盖最旧的日志
+type ringBuffer struct {
+	mutex sync.Mutex
+	lines []string
+	next  int  // 下一条日志写入的位置
+	full  bool // 是否已经写满一轮
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{lines: make([]string, size)}
+}
+
+// 去掉日志行末尾的行结束符后写入
+func (r *ringBuffer) add(logline string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.lines[r.next] = strings.TrimRight(logline, "\r\n")
+	r.next++
+	if r.next == len(r.lines) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// 按写入顺序返回缓冲区中的日志
+func (r *ringBuffer) snapshot() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.full {
+		return append([]string(nil), r.lines[:r.next]...)
+	}
+	recent := make([]string, 0, len(r.lines))
+	recent = append(recent, r.lines[r.next:]...)
+	return append(recent, r.lines[:r.next]...)
+}
+
+// 设置内存中保留的最近日志条数，0 表示关闭，重新设置时清空已保留的日志
+func (l *Log) SetRingBufferSize(size int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if size < 0 {
+		size = 0
+	}
+	l.RingBufferSize = size
+	l.ring = nil
+	if size > 0 {
+		l.ring = newRingBuffer(size)
+	}
+}
+
+func (l *Log) getRing() *ringBuffer {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.ring
+}
+
+// 按时间顺序返回内存中保留的最近日志，不含行结束符，只包含写入协程已处理的日志，
+// 未设置 RingBufferSize 时返回 nil
+func (l *Log) DumpRecent() []string {
+	ring := l.getRing()
+	if ring == nil {
+		return nil
+	}
+	return ring.snapshot()
+}
@@ -0,0 +1,52 @@
+package Logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLog_DumpRecent(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetRingBufferSize(3)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	if recent := LogClient.DumpRecent(); len(recent) != 0 {
+		t.Errorf("expected an empty ring, got %q", recent)
+	}
+	for i := 0; i < 5; i++ {
+		LogClient.Infof("recent message %d", i)
+	}
+	LogClient.Flush()
+
+	recent := LogClient.DumpRecent()
+	if len(recent) != 3 {
+		t.Fatalf("expected exactly 3 recent lines, got %q", recent)
+	}
+	for i, line := range recent {
+		if !strings.HasSuffix(line, fmt.Sprintf("message:recent message %d", i+2)) {
+			t.Errorf("unexpected recent line %d: %q", i, line)
+		}
+	}
+}
+
+func TestRingBuffer_Concurrent(t *testing.T) {
+	ring := newRingBuffer(16)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ring.add(fmt.Sprintf("line %d-%d\n", i, j))
+				_ = ring.snapshot()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if recent := ring.snapshot(); len(recent) != 16 {
+		t.Errorf("expected the ring to stay bounded at 16, got %d", len(recent))
+	}
+}
@@ -0,0 +1,99 @@
+//go:build go1.21
+
+package Logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// 基于本包日志的 slog.Handler，slog 的属性作为结构化字段输出
+type slogHandler struct {
+	logger Logger
+	fields map[string]interface{} // WithAttrs 添加的字段
+	prefix string                 // WithGroup 添加的分组前缀，如 "request."
+}
+
+// 由 *Log 和派生日志实现，以 slog 记录中的程序计数器作为调用处写入日志
+type pcEntryWriter interface {
+	writeEntryPC(e Entry, pc uintptr)
+}
+
+// 创建 slog.Handler，可通过 slog.New(NewSlogHandler(l)) 使用，文件切换和清理仍由 l 负责
+func NewSlogHandler(l Logger) slog.Handler {
+	return &slogHandler{logger: l}
+}
+
+func (l *Log) writeEntryPC(e Entry, pc uintptr) {
+	l.writeEntry(e, nil, pc, 0)
+}
+
+func (d *derivedLogger) writeEntryPC(e Entry, pc uintptr) {
+	d.writeEntry(e, &d.ctx, pc, 0)
+}
+
+// 将 slog 级别映射为本包的级别，介于两个级别之间的取较低的一个
+func levelFromSlog(level slog.Level) int {
+	switch {
+	case level < slog.LevelInfo:
+		return Debug
+	case level < slog.LevelWarn:
+		return Info
+	case level < slog.LevelError:
+		return Warn
+	default:
+		return Error
+	}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return levelFromSlog(level) >= h.logger.Level()
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := mergeFields(h.fields, nil)
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(fields, h.prefix, a)
+		return true
+	})
+	e := Entry{Level: levelFromSlog(r.Level), Time: r.Time, Message: r.Message, Fields: fields}
+	if w, ok := h.logger.(pcEntryWriter); ok && r.PC != 0 {
+		w.writeEntryPC(e, r.PC)
+		return nil
+	}
+	h.logger.WriteEntry(e)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := mergeFields(h.fields, nil)
+	for _, a := range attrs {
+		addSlogAttr(fields, h.prefix, a)
+	}
+	return &slogHandler{logger: h.logger, fields: fields, prefix: h.prefix}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{logger: h.logger, fields: h.fields, prefix: h.prefix + name + "."}
+}
+
+// 将 slog 属性展开为字段，分组内的属性以 "分组.key" 命名
+func addSlogAttr(fields map[string]interface{}, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, attr := range a.Value.Group() {
+			addSlogAttr(fields, prefix, attr)
+		}
+		return
+	}
+	fields[prefix+a.Key] = a.Value.Any()
+}
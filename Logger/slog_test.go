@@ -0,0 +1,34 @@
+//go:build go1.21
+
+package Logger
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetShortCaller(true)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	logger := slog.New(NewSlogHandler(LogClient)).With("service", "api")
+	logger.Warn("slow request", "latency_ms", 250, slog.Group("request", "method", "GET"))
+	logger.Debug("filtered by level")
+	logger.WithGroup("db").Error("query failed", "table", "users")
+
+	content := readTodayLog(t, LogClient, dir)
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", content)
+	}
+	if !strings.HasPrefix(lines[0], "[Warn]") || !strings.Contains(lines[0], "fileLine:slog_test.go:") ||
+		!strings.HasSuffix(lines[0], "message:slow request latency_ms=250 request.method=GET service=api") {
+		t.Errorf("unexpected warn line %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "[Error]") || !strings.HasSuffix(lines[1], "message:query failed db.table=users service=api") {
+		t.Errorf("unexpected error line %q", lines[1])
+	}
+}
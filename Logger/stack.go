@@ -0,0 +1,45 @@
+package Logger
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+)
+
+// 记录 Error 级别日志并附加调用栈，调用栈在消息之后以 stack 分隔行包围，文本格式下其中的换行会被转义
+func (l *Log) ErrorfWithStack(format string, a ...interface{}) {
+	l.syncWriteLog(Error, nil, 0, format+escapeFormat(callerStack(l.CallerSkip)), a...)
+}
+
+func (d *derivedLogger) ErrorfWithStack(format string, a ...interface{}) {
+	d.syncWriteLog(Error, &d.ctx, 0, format+escapeFormat(callerStack(d.CallerSkip)), a...)
+}
+
+// 从 callerStack 到用户调用处之间的日志内部栈帧数：callerStack -> ErrorfWithStack
+const stackDepth = 2
+
+// 获取当前协程的调用栈，去掉首行的协程信息和日志内部的栈帧
+func callerStack(skip int) string {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	// 首行为 "goroutine N [running]:"，之后每个栈帧占两行：函数名和文件行号
+	lines := bytes.Split(bytes.TrimRight(buf, "\n"), []byte("\n"))
+	if drop := 1 + 2*(stackDepth+skip); drop < len(lines) {
+		lines = lines[drop:]
+	} else {
+		lines = nil
+	}
+	return "\n--- stack ---\n" + string(bytes.Join(lines, []byte("\n"))) + "\n--- end stack ---"
+}
+
+// 转义 %，使字符串可以直接拼接在格式化模板中
+func escapeFormat(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
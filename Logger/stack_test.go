@@ -0,0 +1,34 @@
+package Logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func logErrorWithStack(LogClient Logger) {
+	LogClient.ErrorfWithStack("stack message %d", 1)
+}
+
+func TestLog_ErrorfWithStack(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	logErrorWithStack(LogClient)
+	LogClient.Named("db").ErrorfWithStack("derived stack message")
+
+	content := readTodayLog(t, LogClient, dir)
+	if !strings.Contains(content, "[Error]") || !strings.Contains(content, "message:stack message 1\\n--- stack ---\\n") {
+		t.Fatalf("expected delimited stack after message, got %q", content)
+	}
+	stack := content[strings.Index(content, "--- stack ---"):strings.Index(content, "--- end stack ---")]
+	if !strings.Contains(stack, "logErrorWithStack") || !strings.Contains(stack, "TestLog_ErrorfWithStack") {
+		t.Errorf("expected test call chain in stack, got %q", stack)
+	}
+	if strings.Contains(stack, "callerStack") || strings.Contains(stack, "[running]") {
+		t.Errorf("expected logger frames to be trimmed, got %q", stack)
+	}
+	if strings.Count(content, "--- end stack ---") != 2 {
+		t.Errorf("expected stacks for both loggers, got %q", content)
+	}
+}
@@ -0,0 +1,57 @@
+package Logger
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// 日志写入的统计数据
+type Stats struct {
+	LinesWritten int64 // 已写入的日志行数
+	BytesWritten int64 // 已写入的字节数
+	LinesDropped int64 // 因通道已满丢弃的日志行数
+	Rotations    int64 // 切换文件的次数
+	WriteErrors  int64 // 写入文件或输出目标失败的次数
+}
+
+// 获取统计数据，各计数器原子读取，可在任意协程中调用
+func (l *Log) Stats() Stats {
+	return Stats{
+		LinesWritten: atomic.LoadInt64(&l.linesWritten),
+		BytesWritten: atomic.LoadInt64(&l.bytesWritten),
+		LinesDropped: atomic.LoadInt64(&l.dropped),
+		Rotations:    atomic.LoadInt64(&l.rotations),
+		WriteErrors:  atomic.LoadInt64(&l.writeErrors),
+	}
+}
+
+// 记录一行已写入的日志
+func (l *Log) recordWrite(n int) {
+	l.recordWrites(1, n)
+}
+
+// 记录一次写入的多行日志，lines 为行数，n 为字节数
+func (l *Log) recordWrites(lines, n int) {
+	atomic.AddInt64(&l.linesWritten, int64(lines))
+	atomic.AddInt64(&l.bytesWritten, int64(n))
+}
+
+// 两次写入失败警告之间的最小间隔
+const writeWarnInterval = time.Minute
+
+// 记录一次写入失败，警告输出到标准错误并限制频率，避免磁盘故障时刷屏
+func (l *Log) writeFailed(err error) {
+	failures := atomic.AddInt64(&l.writeErrors, 1)
+	// 设置了错误处理函数时每次失败都交给它，不做限频
+	if l.getInternalErrorHandler() != nil {
+		l.internalError("write log", err)
+		return
+	}
+	now := l.clockNow()
+	if !l.lastWriteWarn.IsZero() && now.Sub(l.lastWriteWarn) < writeWarnInterval {
+		return
+	}
+	l.lastWriteWarn = now
+	log.Printf("Failed to write log: %v (%d failures so far)\n", err, failures)
+}
@@ -0,0 +1,117 @@
+package Logger
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLog_Stats(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetMaxSize(500)
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	for i := 0; i < 10; i++ {
+		LogClient.Infof("stats message %d", i)
+	}
+	LogClient.Debugf("filtered by level")
+	LogClient.Flush()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var size int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		size += info.Size()
+	}
+	stats := LogClient.Stats()
+	if stats.LinesWritten != 10 {
+		t.Errorf("LinesWritten = %d, want 10", stats.LinesWritten)
+	}
+	if stats.BytesWritten != size {
+		t.Errorf("BytesWritten = %d, want %d", stats.BytesWritten, size)
+	}
+	if want := int64(len(entries) - 1); want == 0 || stats.Rotations != want {
+		t.Errorf("Rotations = %d, want %d", stats.Rotations, want)
+	}
+	if stats.LinesDropped != 0 {
+		t.Errorf("LinesDropped = %d, want 0", stats.LinesDropped)
+	}
+}
+
+// 每次写入都失败的输出目标
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk failure")
+}
+
+func TestLog_WriteErrors(t *testing.T) {
+	var warnings syncBuffer
+	log.SetOutput(&warnings)
+	defer log.SetOutput(os.Stderr)
+
+	LogClient := NewLogger()
+	LogClient.SetOutput(failingWriter{})
+	LogClient.SetLogger(Info, t.TempDir(), 6)
+	defer LogClient.Close()
+	for i := 0; i < 3; i++ {
+		LogClient.Infof("lost message %d", i)
+	}
+	LogClient.Flush()
+
+	stats := LogClient.Stats()
+	if stats.WriteErrors != 3 || stats.LinesWritten != 0 {
+		t.Errorf("WriteErrors = %d, LinesWritten = %d, want 3 and 0", stats.WriteErrors, stats.LinesWritten)
+	}
+	if got := warnings.String(); strings.Count(got, "Failed to write log: disk failure") != 1 {
+		t.Errorf("expected a single throttled warning, got %q", got)
+	}
+}
+
+func TestLog_StatsSkipFailedBatch(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	LogClient := NewLogger()
+	LogClient.SetInternalErrorHandler(func(error) {})
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	LogClient.Infof("written message")
+	LogClient.Flush()
+	before := LogClient.Stats()
+	l := LogClient.(*Log)
+	// 在写入协程中关闭文件，并用普通文件占住日志目录，使写入和恢复都失败
+	err := l.runInWriter(func() error {
+		l.mutex.Lock()
+		_ = l.currentFile.Close()
+		l.mutex.Unlock()
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		return os.WriteFile(dir, nil, 0666)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		LogClient.Infof("lost message %d", i)
+	}
+	LogClient.Flush()
+
+	stats := LogClient.Stats()
+	if stats.WriteErrors == 0 {
+		t.Error("expected the failed writes to be counted")
+	}
+	if before.LinesWritten != 1 || stats.LinesWritten != before.LinesWritten || stats.BytesWritten != before.BytesWritten {
+		t.Errorf("LinesWritten %d -> %d, BytesWritten %d -> %d, want only the first line counted",
+			before.LinesWritten, stats.LinesWritten, before.BytesWritten, stats.BytesWritten)
+	}
+}
@@ -0,0 +1,50 @@
+//go:build !windows && !plan9
+
+package Logger
+
+import (
+	"log/syslog"
+)
+
+// 系统日志输出，按日志级别写入对应的 syslog 等级
+type syslogWriter struct {
+	writer *syslog.Writer
+}
+
+// 连接系统日志，network 和 addr 为空时连接本机 syslog 服务，与文件输出同时生效
+func (l *Log) SetSyslog(network, addr, tag string) error {
+	writer, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return err
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.syslogWriter != nil {
+		_ = l.syslogWriter.close()
+	}
+	l.syslogWriter = &syslogWriter{writer: writer}
+	return nil
+}
+
+func (l *Log) getSyslog() *syslogWriter {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.syslogWriter
+}
+
+func (s *syslogWriter) write(level int, line string) error {
+	switch level {
+	case Debug:
+		return s.writer.Debug(line)
+	case Warn:
+		return s.writer.Warning(line)
+	case Error:
+		return s.writer.Err(line)
+	default:
+		return s.writer.Info(line)
+	}
+}
+
+func (s *syslogWriter) close() error {
+	return s.writer.Close()
+}
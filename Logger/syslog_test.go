@@ -0,0 +1,44 @@
+//go:build !windows && !plan9
+
+package Logger
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLog_SetSyslog(t *testing.T) {
+	dir := t.TempDir()
+	addr := filepath.Join(dir, "syslog.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	if err = LogClient.SetSyslog("unixgram", addr, "logtest"); err != nil {
+		t.Fatal(err)
+	}
+	LogClient.Errorf("syslog error message")
+
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// LOG_USER(8) | LOG_ERR(3)
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<11>") || !strings.Contains(msg, "syslog error message") {
+		t.Errorf("unexpected syslog message: %q", msg)
+	}
+	if content := readTodayLog(t, LogClient, dir); !strings.Contains(content, "syslog error message") {
+		t.Errorf("file output missing with syslog enabled: %q", content)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build windows || plan9
+
+package Logger
+
+import (
+	"errors"
+)
+
+// 当前平台不支持系统日志
+type syslogWriter struct{}
+
+func (l *Log) SetSyslog(network, addr, tag string) error {
+	return errors.New("syslog is not supported on this platform")
+}
+
+func (l *Log) getSyslog() *syslogWriter {
+	return nil
+}
+
+func (s *syslogWriter) write(level int, line string) error {
+	return nil
+}
+
+func (s *syslogWriter) close() error {
+	return nil
+}
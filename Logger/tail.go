@@ -0,0 +1,69 @@
+package Logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// 从文件末尾向前读取时每次读取的字节数
+const tailChunkSize = 4096
+
+// 读取当前日志文件的最后 n 行，文件不足 n 行时返回全部行
+func (l *Log) ReadLast(n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	l.Flush()
+	l.mutex.Lock()
+	if l.currentFile == nil {
+		l.mutex.Unlock()
+		return nil, fmt.Errorf("no active log file")
+	}
+	path := l.currentFile.Name()
+	l.mutex.Unlock()
+
+	File, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer File.Close()
+	return readLastLines(File, n)
+}
+
+// 从末尾按块向前读取，直到读到 n 个完整行或到达文件开头
+func readLastLines(File *os.File, n int) ([]string, error) {
+	info, err := File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size()
+	var data []byte
+	// 末尾的换行属于最后一行，因此需要 n+1 个换行才能保证第一行完整
+	for offset > 0 && bytes.Count(data, []byte("\n")) <= n {
+		size := int64(tailChunkSize)
+		if offset < size {
+			size = offset
+		}
+		offset -= size
+		chunk := make([]byte, size)
+		if _, err := File.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		data = append(chunk, data...)
+	}
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines, nil
+}
@@ -0,0 +1,61 @@
+package Logger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLog_ReadLast(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	defer LogClient.Close()
+	LogClient.SetLogger(Info, dir, 6)
+	for i := 1; i <= 5; i++ {
+		LogClient.Infof("line %d", i)
+	}
+
+	lines, err := LogClient.ReadLast(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %q", len(lines), lines)
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, fmt.Sprintf("line %d", i+3)) {
+			t.Errorf("unexpected line %d: %q", i, line)
+		}
+	}
+
+	lines, err = LogClient.ReadLast(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 5 || !strings.HasSuffix(lines[0], "line 1") {
+		t.Errorf("expected all 5 lines when the file is shorter than n, got %q", lines)
+	}
+}
+
+func TestLog_ReadLastLongFile(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	defer LogClient.Close()
+	LogClient.SetLogger(Info, dir, 6)
+	// 写入超过一个读取块大小的内容，验证跨块读取
+	for i := 0; i < 200; i++ {
+		LogClient.Infof("padding %s", strings.Repeat("x", 64))
+	}
+	LogClient.Infof("last line")
+
+	lines, err := LogClient.ReadLast(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 100 || !strings.HasSuffix(lines[99], "last line") || !strings.Contains(lines[0], "padding") {
+		t.Errorf("unexpected tail of long file: %d lines", len(lines))
+	}
+	if strings.Contains(lines[0], "\n") || !strings.HasPrefix(lines[0], "[Info]") {
+		t.Errorf("first line should be complete: %q", lines[0])
+	}
+}
@@ -0,0 +1,577 @@
+package Logger
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// 将每次调用转发给多个日志的组合日志
+type teeLogger struct {
+	loggers []Logger
+}
+
+// 从 teeLogger 的方法到被包装日志的方法多出的栈深度
+const teeCallerSkip = 1
+
+// 由 *Log、派生日志和 teeLogger 实现，skip 为转发多出的栈深度，
+// teeLogger 按次传入自身的栈深度，不修改被包装日志的 CallerSkip
+type skipLogger interface {
+	logSkip(skip, level int, format string, a ...interface{})
+	errorWithStackSkip(skip int, format string, a ...interface{})
+	writeEntrySkip(skip int, e Entry)
+}
+
+// 创建组合日志，每次调用都会依次转发给 loggers，某个日志返回错误不影响其他日志。
+// 转发时额外跳过 teeLogger 自身的栈帧，使调用处仍指向用户代码，被包装日志单独使用时不受影响
+func NewTeeLogger(loggers ...Logger) Logger {
+	return &teeLogger{loggers: loggers}
+}
+
+func (l *Log) logSkip(skip, level int, format string, a ...interface{}) {
+	l.syncWriteLog(level, nil, skip, format, a...)
+}
+
+func (l *Log) errorWithStackSkip(skip int, format string, a ...interface{}) {
+	l.syncWriteLog(Error, nil, skip, format+escapeFormat(callerStack(l.CallerSkip+skip)), a...)
+}
+
+func (l *Log) writeEntrySkip(skip int, e Entry) {
+	l.writeEntry(e, nil, 0, skip)
+}
+
+func (d *derivedLogger) logSkip(skip, level int, format string, a ...interface{}) {
+	d.syncWriteLog(level, &d.ctx, skip, format, a...)
+}
+
+func (d *derivedLogger) errorWithStackSkip(skip int, format string, a ...interface{}) {
+	d.syncWriteLog(Error, &d.ctx, skip, format+escapeFormat(callerStack(d.CallerSkip+skip)), a...)
+}
+
+func (d *derivedLogger) writeEntrySkip(skip int, e Entry) {
+	d.writeEntry(e, &d.ctx, 0, skip)
+}
+
+func (t *teeLogger) logSkip(skip, level int, format string, a ...interface{}) {
+	t.logf(skip+teeCallerSkip, level, format, a...)
+}
+
+func (t *teeLogger) errorWithStackSkip(skip int, format string, a ...interface{}) {
+	t.errorWithStack(skip+teeCallerSkip, format, a...)
+}
+
+func (t *teeLogger) writeEntrySkip(skip int, e Entry) {
+	for _, l := range t.loggers {
+		if s, ok := l.(skipLogger); ok {
+			s.writeEntrySkip(skip+teeCallerSkip, e)
+		} else {
+			l.WriteEntry(e)
+		}
+	}
+}
+
+// 转发一条日志，skip 为从用户调用处到 logf 的调用者之间 teeLogger 自身的栈深度，
+// 未实现 skipLogger 的日志按级别调用对应的公开方法
+func (t *teeLogger) logf(skip, level int, format string, a ...interface{}) {
+	for _, l := range t.loggers {
+		if s, ok := l.(skipLogger); ok {
+			s.logSkip(skip+1, level, format, a...)
+			continue
+		}
+		switch level {
+		case Debug:
+			l.Debugf(format, a...)
+		case Info:
+			l.Infof(format, a...)
+		case Warn:
+			l.Warnf(format, a...)
+		default:
+			l.Errorf(format, a...)
+		}
+	}
+}
+
+// 转发一条附带调用栈的 Error 日志，skip 的含义与 logf 相同
+func (t *teeLogger) errorWithStack(skip int, format string, a ...interface{}) {
+	for _, l := range t.loggers {
+		if s, ok := l.(skipLogger); ok {
+			s.errorWithStackSkip(skip+1, format, a...)
+		} else {
+			l.ErrorfWithStack(format, a...)
+		}
+	}
+}
+
+// 依次调用 fn，返回第一个错误
+func (t *teeLogger) each(fn func(l Logger) error) error {
+	var first error
+	for _, l := range t.loggers {
+		if err := fn(l); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (t *teeLogger) SetLogger(Level int, FilePath string, MaxDay int64) error {
+	return t.each(func(l Logger) error { return l.SetLogger(Level, FilePath, MaxDay) })
+}
+
+func (t *teeLogger) Debugf(format string, a ...interface{}) {
+	t.logf(teeCallerSkip, Debug, format, a...)
+}
+
+func (t *teeLogger) Errorf(format string, a ...interface{}) {
+	t.logf(teeCallerSkip, Error, format, a...)
+}
+
+func (t *teeLogger) ErrorfWithStack(format string, a ...interface{}) {
+	t.errorWithStack(teeCallerSkip, format, a...)
+}
+
+func (t *teeLogger) Warnf(format string, a ...interface{}) {
+	t.logf(teeCallerSkip, Warn, format, a...)
+}
+
+func (t *teeLogger) Infof(format string, a ...interface{}) {
+	t.logf(teeCallerSkip, Info, format, a...)
+}
+
+func (t *teeLogger) InfofCtx(ctx context.Context, format string, a ...interface{}) {
+	t.logf(teeCallerSkip, Info, contextPrefix(ctx)+format, a...)
+}
+
+func (t *teeLogger) ErrorfCtx(ctx context.Context, format string, a ...interface{}) {
+	t.logf(teeCallerSkip, Error, contextPrefix(ctx)+format, a...)
+}
+
+func (t *teeLogger) WriteEntry(e Entry) {
+	t.writeEntrySkip(teeCallerSkip, e)
+}
+
+func (t *teeLogger) InfoRaw(msg string) {
+	for _, l := range t.loggers {
+		l.InfoRaw(msg)
+	}
+}
+
+func (t *teeLogger) ErrorRaw(msg string) {
+	for _, l := range t.loggers {
+		l.ErrorRaw(msg)
+	}
+}
+
+// 所有日志写完后再退出，避免只有第一个日志写入
+func (t *teeLogger) Fatalf(format string, a ...interface{}) {
+	t.logf(teeCallerSkip, Error, format, a...)
+	t.Flush()
+	exitFunc(1)
+}
+
+// 捕获 panic 后由每个日志分别记录，recover 只能在 defer 直接调用的函数中生效，因此不能转发 RecoverAndLog
+func (t *teeLogger) RecoverAndLog(rethrow bool) {
+	if r := recover(); r != nil {
+		t.errorWithStack(teeCallerSkip, "panic: %v", r)
+		t.Flush()
+		if rethrow {
+			panic(r)
+		}
+	}
+}
+
+// 返回同时写入所有日志的 io.Writer
+func (t *teeLogger) Writer(level int) io.Writer {
+	writers := make([]io.Writer, len(t.loggers))
+	for i, l := range t.loggers {
+		writers[i] = l.Writer(level)
+	}
+	return io.MultiWriter(writers...)
+}
+
+func (t *teeLogger) SetCallerSkip(skip int) {
+	for _, l := range t.loggers {
+		l.SetCallerSkip(skip)
+	}
+}
+
+func (t *teeLogger) SetMaxSize(MaxSize int64) {
+	for _, l := range t.loggers {
+		l.SetMaxSize(MaxSize)
+	}
+}
+
+func (t *teeLogger) SetOutput(w io.Writer) {
+	for _, l := range t.loggers {
+		l.SetOutput(w)
+	}
+}
+
+func (t *teeLogger) SetConsoleOutput(enable bool) {
+	for _, l := range t.loggers {
+		l.SetConsoleOutput(enable)
+	}
+}
+
+func (t *teeLogger) SetColor(enable bool) {
+	for _, l := range t.loggers {
+		l.SetColor(enable)
+	}
+}
+
+func (t *teeLogger) SetForceColor(force bool) {
+	for _, l := range t.loggers {
+		l.SetForceColor(force)
+	}
+}
+
+func (t *teeLogger) SetFullFuncName(enable bool) {
+	for _, l := range t.loggers {
+		l.SetFullFuncName(enable)
+	}
+}
+
+func (t *teeLogger) SetSyncOnError(enable bool) {
+	for _, l := range t.loggers {
+		l.SetSyncOnError(enable)
+	}
+}
+
+func (t *teeLogger) SetBackupNameScheme(scheme int) {
+	for _, l := range t.loggers {
+		l.SetBackupNameScheme(scheme)
+	}
+}
+
+func (t *teeLogger) SetLevelStyle(style int) {
+	for _, l := range t.loggers {
+		l.SetLevelStyle(style)
+	}
+}
+
+func (t *teeLogger) SetShortCaller(enable bool) {
+	for _, l := range t.loggers {
+		l.SetShortCaller(enable)
+	}
+}
+
+func (t *teeLogger) SetDisableCaller(disable bool) {
+	for _, l := range t.loggers {
+		l.SetDisableCaller(disable)
+	}
+}
+
+func (t *teeLogger) SetSymlinkCurrent(enable bool) {
+	for _, l := range t.loggers {
+		l.SetSymlinkCurrent(enable)
+	}
+}
+
+func (t *teeLogger) SetShowSequence(enable bool) {
+	for _, l := range t.loggers {
+		l.SetShowSequence(enable)
+	}
+}
+
+func (t *teeLogger) SetShowGoroutineID(enable bool) {
+	for _, l := range t.loggers {
+		l.SetShowGoroutineID(enable)
+	}
+}
+
+func (t *teeLogger) SetOnRotate(fn func(oldPath, newPath string)) {
+	for _, l := range t.loggers {
+		l.SetOnRotate(fn)
+	}
+}
+
+func (t *teeLogger) SetFormat(format int) {
+	for _, l := range t.loggers {
+		l.SetFormat(format)
+	}
+}
+
+func (t *teeLogger) SetFormatter(f Formatter) {
+	for _, l := range t.loggers {
+		l.SetFormatter(f)
+	}
+}
+
+func (t *teeLogger) SetCompress(enable bool) {
+	for _, l := range t.loggers {
+		l.SetCompress(enable)
+	}
+}
+
+func (t *teeLogger) SetCompressOnRotate(enable bool) {
+	for _, l := range t.loggers {
+		l.SetCompressOnRotate(enable)
+	}
+}
+
+func (t *teeLogger) SetSeparateErrorFile(enable bool) {
+	for _, l := range t.loggers {
+		l.SetSeparateErrorFile(enable)
+	}
+}
+
+func (t *teeLogger) SetMaxBackups(MaxBackups int) {
+	for _, l := range t.loggers {
+		l.SetMaxBackups(MaxBackups)
+	}
+}
+
+func (t *teeLogger) SetMaxTotalSize(MaxTotalSize int64) {
+	for _, l := range t.loggers {
+		l.SetMaxTotalSize(MaxTotalSize)
+	}
+}
+
+func (t *teeLogger) SetPermissions(DirPerm, FilePerm os.FileMode) {
+	for _, l := range t.loggers {
+		l.SetPermissions(DirPerm, FilePerm)
+	}
+}
+
+func (t *teeLogger) SetTimeFormat(TimeFormat string) {
+	for _, l := range t.loggers {
+		l.SetTimeFormat(TimeFormat)
+	}
+}
+
+func (t *teeLogger) SetLineTerminator(terminator string) {
+	for _, l := range t.loggers {
+		l.SetLineTerminator(terminator)
+	}
+}
+
+func (t *teeLogger) SetMaxMessageBytes(n int) {
+	for _, l := range t.loggers {
+		l.SetMaxMessageBytes(n)
+	}
+}
+
+func (t *teeLogger) SetUseUTC(enable bool) {
+	for _, l := range t.loggers {
+		l.SetUseUTC(enable)
+	}
+}
+
+func (t *teeLogger) SetFileNamePattern(pattern FileNamePattern) {
+	for _, l := range t.loggers {
+		l.SetFileNamePattern(pattern)
+	}
+}
+
+func (t *teeLogger) SetRotateInterval(interval int) {
+	for _, l := range t.loggers {
+		l.SetRotateInterval(interval)
+	}
+}
+
+func (t *teeLogger) SetDropWhenFull(enable bool) {
+	for _, l := range t.loggers {
+		l.SetDropWhenFull(enable)
+	}
+}
+
+func (t *teeLogger) SetSendTimeout(timeout time.Duration) {
+	for _, l := range t.loggers {
+		l.SetSendTimeout(timeout)
+	}
+}
+
+func (t *teeLogger) SetBufferSize(size int) {
+	for _, l := range t.loggers {
+		l.SetBufferSize(size)
+	}
+}
+
+func (t *teeLogger) SetFlushInterval(interval time.Duration) {
+	for _, l := range t.loggers {
+		l.SetFlushInterval(interval)
+	}
+}
+
+func (t *teeLogger) SetHTTPSink(cfg HTTPSinkConfig) {
+	for _, l := range t.loggers {
+		l.SetHTTPSink(cfg)
+	}
+}
+
+func (t *teeLogger) AddHook(h Hook) {
+	for _, l := range t.loggers {
+		l.AddHook(h)
+	}
+}
+
+func (t *teeLogger) SetFilter(fn func(level int, msg string) bool) {
+	for _, l := range t.loggers {
+		l.SetFilter(fn)
+	}
+}
+
+func (t *teeLogger) SetRateLimit(level int, perSecond float64, burst int) {
+	for _, l := range t.loggers {
+		l.SetRateLimit(level, perSecond, burst)
+	}
+}
+
+func (t *teeLogger) SetDedup(timeout time.Duration) {
+	for _, l := range t.loggers {
+		l.SetDedup(timeout)
+	}
+}
+
+func (t *teeLogger) SetLevel(level int) {
+	for _, l := range t.loggers {
+		l.SetLevel(level)
+	}
+}
+
+func (t *teeLogger) SetSyslog(network, addr, tag string) error {
+	return t.each(func(l Logger) error { return l.SetSyslog(network, addr, tag) })
+}
+
+func (t *teeLogger) WithFields(fields map[string]interface{}) Logger {
+	derived := make([]Logger, len(t.loggers))
+	for i, l := range t.loggers {
+		derived[i] = l.WithFields(fields)
+	}
+	return &teeLogger{loggers: derived}
+}
+
+func (t *teeLogger) WithError(err error) Logger {
+	if err == nil {
+		return t
+	}
+	return t.WithFields(errorFields(err))
+}
+
+func (t *teeLogger) WithTag(key, value string) Logger {
+	derived := make([]Logger, len(t.loggers))
+	for i, l := range t.loggers {
+		derived[i] = l.WithTag(key, value)
+	}
+	return &teeLogger{loggers: derived}
+}
+
+func (t *teeLogger) Named(name string) Logger {
+	derived := make([]Logger, len(t.loggers))
+	for i, l := range t.loggers {
+		derived[i] = l.Named(name)
+	}
+	return &teeLogger{loggers: derived}
+}
+
+func (t *teeLogger) SetPath(newPath string) error {
+	return t.each(func(l Logger) error { return l.SetPath(newPath) })
+}
+
+func (t *teeLogger) Reopen() error {
+	return t.each(func(l Logger) error { return l.Reopen() })
+}
+
+func (t *teeLogger) Rotate() error {
+	return t.each(func(l Logger) error { return l.Rotate() })
+}
+
+// 所有日志丢弃条数之和
+func (t *teeLogger) DroppedCount() int64 {
+	var dropped int64
+	for _, l := range t.loggers {
+		dropped += l.DroppedCount()
+	}
+	return dropped
+}
+
+// 读取第一个日志的最后 n 行
+func (t *teeLogger) ReadLast(n int) ([]string, error) {
+	if len(t.loggers) == 0 {
+		return nil, nil
+	}
+	return t.loggers[0].ReadLast(n)
+}
+
+func (t *teeLogger) SetInternalErrorHandler(fn func(error)) {
+	for _, l := range t.loggers {
+		l.SetInternalErrorHandler(fn)
+	}
+}
+
+func (t *teeLogger) SetRingBufferSize(size int) {
+	for _, l := range t.loggers {
+		l.SetRingBufferSize(size)
+	}
+}
+
+// 第一个日志保留的最近日志
+func (t *teeLogger) DumpRecent() []string {
+	if len(t.loggers) == 0 {
+		return nil
+	}
+	return t.loggers[0].DumpRecent()
+}
+
+// 所有日志统计数据之和
+func (t *teeLogger) Stats() Stats {
+	var stats Stats
+	for _, l := range t.loggers {
+		s := l.Stats()
+		stats.LinesWritten += s.LinesWritten
+		stats.BytesWritten += s.BytesWritten
+		stats.LinesDropped += s.LinesDropped
+		stats.Rotations += s.Rotations
+		stats.WriteErrors += s.WriteErrors
+	}
+	return stats
+}
+
+// 返回所有日志中最低的级别，即只要有一个日志会输出该级别就视为开启
+func (t *teeLogger) Level() int {
+	level := 0
+	for _, l := range t.loggers {
+		if current := l.Level(); level == 0 || current < level {
+			level = current
+		}
+	}
+	return level
+}
+
+// 任一日志会输出该级别时返回 true
+func (t *teeLogger) IsLevelEnabled(level int) bool {
+	for _, l := range t.loggers {
+		if l.IsLevelEnabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *teeLogger) GetConf() {
+	for _, l := range t.loggers {
+		l.GetConf()
+	}
+}
+
+// 返回第一个日志的配置
+func (t *teeLogger) GetConfig() Config {
+	if len(t.loggers) == 0 {
+		return Config{}
+	}
+	return t.loggers[0].GetConfig()
+}
+
+func (t *teeLogger) Flush() {
+	for _, l := range t.loggers {
+		l.Flush()
+	}
+}
+
+func (t *teeLogger) Close() error {
+	return t.each(func(l Logger) error { return l.Close() })
+}
+
+func (t *teeLogger) CloseWithTimeout(d time.Duration) error {
+	return t.each(func(l Logger) error { return l.CloseWithTimeout(d) })
+}
@@ -0,0 +1,122 @@
+package Logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// 记录收到的调用的日志，其余方法沿用 nopLogger
+type recordingLogger struct {
+	nopLogger
+	calls    []string
+	closeErr error
+}
+
+func (r *recordingLogger) record(call string) {
+	r.calls = append(r.calls, call)
+}
+
+func (r *recordingLogger) Debugf(format string, a ...interface{}) {
+	r.record("Debugf " + fmt.Sprintf(format, a...))
+}
+
+func (r *recordingLogger) Infof(format string, a ...interface{}) {
+	r.record("Infof " + fmt.Sprintf(format, a...))
+}
+
+func (r *recordingLogger) Errorf(format string, a ...interface{}) {
+	r.record("Errorf " + fmt.Sprintf(format, a...))
+}
+
+func (r *recordingLogger) WriteEntry(e Entry) {
+	r.record("WriteEntry " + e.Message)
+}
+
+func (r *recordingLogger) SetLevel(level int) {
+	r.record(fmt.Sprintf("SetLevel %d", level))
+}
+
+func (r *recordingLogger) Flush() {
+	r.record("Flush")
+}
+
+func (r *recordingLogger) Close() error {
+	r.record("Close")
+	return r.closeErr
+}
+
+func TestTeeLogger(t *testing.T) {
+	first := &recordingLogger{closeErr: errors.New("close failed")}
+	second := &recordingLogger{}
+	LogClient := NewTeeLogger(first, second)
+	LogClient.SetLevel(Debug)
+	LogClient.Debugf("debug %d", 1)
+	LogClient.Infof("info %d", 2)
+	LogClient.Errorf("error %d", 3)
+	LogClient.WriteEntry(Entry{Level: Info, Message: "entry"})
+	LogClient.Flush()
+	if err := LogClient.Close(); err == nil {
+		t.Error("expected close error from the first logger")
+	}
+
+	want := []string{"SetLevel 1", "Debugf debug 1", "Infof info 2", "Errorf error 3", "WriteEntry entry", "Flush", "Close"}
+	for _, r := range []*recordingLogger{first, second} {
+		if strings.Join(r.calls, "|") != strings.Join(want, "|") {
+			t.Errorf("calls = %q, want %q", r.calls, want)
+		}
+	}
+}
+
+func TestTeeLogger_Caller(t *testing.T) {
+	dirs := []string{t.TempDir(), t.TempDir()}
+	loggers := make([]Logger, len(dirs))
+	for i, dir := range dirs {
+		loggers[i] = NewLogger()
+		loggers[i].SetLogger(Info, dir, 6)
+	}
+	LogClient := NewTeeLogger(loggers...)
+	defer LogClient.Close()
+	_, file, line, _ := runtime.Caller(0)
+	LogClient.Named("tee").Infof("tee message")
+
+	for i, dir := range dirs {
+		content := readTodayLog(t, loggers[i], dir)
+		if want := fmt.Sprintf("[tee] fileLine:%s:%d ", file, line+1); !strings.Contains(content, want) {
+			t.Errorf("expected %q in %q", want, content)
+		}
+	}
+}
+
+func TestTeeLogger_KeepsWrappedCallerSkip(t *testing.T) {
+	dir := t.TempDir()
+	inner := NewLogger()
+	inner.SetLogger(Info, dir, 6)
+	defer inner.Close()
+	inner.SetCallerSkip(2)
+	LogClient := NewTeeLogger(NewTeeLogger(inner))
+	if skip := inner.(*Log).CallerSkip; skip != 2 {
+		t.Fatalf("NewTeeLogger changed the wrapped CallerSkip to %d", skip)
+	}
+	inner.SetCallerSkip(0)
+
+	_, file, line, _ := runtime.Caller(0)
+	inner.Infof("direct message")
+	LogClient.Infof("nested tee message")
+	LogClient.WriteEntry(Entry{Level: Info, Message: "nested tee entry"})
+	LogClient.ErrorfCtx(context.Background(), "nested tee ctx")
+
+	content := readTodayLog(t, inner, dir)
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %q", content)
+	}
+	for i, l := range lines {
+		if want := fmt.Sprintf("fileLine:%s:%d ", file, line+1+i); !strings.Contains(l, want) {
+			t.Errorf("expected %q in %q", want, l)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package Logger
+
+import (
+	"io"
+	"strings"
+)
+
+// 将写入的内容作为一条日志输出的适配器，可用于 log.SetOutput 等只接受 io.Writer 的场景
+type levelWriter struct {
+	log   *Log
+	level int
+	ctx   *logContext
+}
+
+// 返回以 level 级别写日志的 io.Writer，每次 Write 的内容去掉末尾换行后作为一条日志，
+// 调用处无法确定，日志行中不包含调用处信息
+func (l *Log) Writer(level int) io.Writer {
+	return &levelWriter{log: l, level: level}
+}
+
+func (d *derivedLogger) Writer(level int) io.Writer {
+	return &levelWriter{log: d.Log, level: level, ctx: &d.ctx}
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	l := w.log
+	if w.level < l.Level() || !l.limiter.allow(w.level, l.clockNow()) {
+		return len(p), nil
+	}
+	msg := l.truncateMessage(strings.TrimSuffix(string(p), "\n"))
+	l.enqueue(l.newLogLine(w.level, w.ctx, nil, msg))
+	return len(p), nil
+}
@@ -0,0 +1,25 @@
+package Logger
+
+import (
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestLog_Writer(t *testing.T) {
+	dir := t.TempDir()
+	LogClient := NewLogger()
+	LogClient.SetLogger(Info, dir, 6)
+	defer LogClient.Close()
+	std := log.New(LogClient.Writer(Warn), "lib: ", 0)
+	std.Printf("third party message %d", 1)
+	log.New(LogClient.Named("db").Writer(Debug), "", 0).Println("filtered by level")
+
+	content := readTodayLog(t, LogClient, dir)
+	if !strings.Contains(content, "[Warn]") || !strings.HasSuffix(content, " message:lib: third party message 1\n") {
+		t.Errorf("expected library output in the file, got %q", content)
+	}
+	if strings.Contains(content, "filtered by level") {
+		t.Errorf("expected writes below the level to be dropped, got %q", content)
+	}
+}